@@ -2,11 +2,14 @@ package cli
 
 import (
 	"bufio"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -82,19 +85,102 @@ func checkAlreadyRunning() error {
 	return fmt.Errorf("golos is already running (PID %d)\n  Stop it first with: golos stop", pid)
 }
 
+// parseInterspersed parses flags given before or after the positional
+// arguments, as in "golos import words.csv --merge", and returns the
+// positional ones.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		_ = fs.Parse(args)
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
 func DictImport(args []string) {
-	if len(args) < 1 {
-		fmt.Fprintln(os.Stderr, "usage: golos import <file.toml>")
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	merge := fs.Bool("merge", false, "keep existing entries when a phrase conflicts")
+	replace := fs.Bool("replace", false, "overwrite existing entries when a phrase conflicts (default)")
+	files := parseInterspersed(fs, args)
+	if len(files) != 1 || (*merge && *replace) {
+		fmt.Fprintln(os.Stderr, "usage: golos import [--merge|--replace] <file.toml|.json|.csv>")
 		os.Exit(1)
 	}
 
+	mode := internal.ImportReplace
+	if *merge {
+		mode = internal.ImportMerge
+	}
+
 	d := internal.LoadDictionary()
-	count, err := d.Import(args[0])
+	res, err := d.Import(files[0], mode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("imported %d entries (%d updated, %d kept)\n", res.Added+res.Updated, res.Updated, res.Skipped)
+	if len(res.Conflict) > 0 {
+		verb := "overwritten"
+		if mode == internal.ImportMerge {
+			verb = "kept"
+		}
+		fmt.Printf("conflicts (%s): %s\n", verb, strings.Join(res.Conflict, ", "))
+	}
+
+	offerCollapse(d)
+}
+
+// offerCollapse lists entries that differ only in punctuation or casing and,
+// if the user confirms at a terminal, merges each group into a single entry.
+func offerCollapse(d *internal.Dictionary) {
+	groups := d.Variants()
+	if len(groups) == 0 {
+		return
+	}
+
+	norms := make([]string, 0, len(groups))
+	for norm := range groups {
+		norms = append(norms, norm)
+	}
+	sort.Strings(norms)
+
+	fmt.Println()
+	fmt.Println("These entries differ only in punctuation or casing:")
+	for _, norm := range norms {
+		quoted := make([]string, len(groups[norm]))
+		for i, k := range groups[norm] {
+			quoted[i] = strconv.Quote(k)
+		}
+		fmt.Printf("  %q ← %s\n", norm, strings.Join(quoted, ", "))
+	}
+	if !stdinIsTerminal() {
+		return
+	}
+	fmt.Print("Collapse each group into one entry? [y/N]: ")
+
+	reader := bufio.NewReader(os.Stdin)
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		return
+	}
+
+	removed, err := d.Collapse()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Printf("imported %d entries\n", count)
+	fmt.Printf("collapsed %d variant entries\n", removed)
+}
+
+// stdinIsTerminal reports whether stdin is a terminal a person can answer
+// prompts on.
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
 }
 
 func DictAdd(args []string) {
@@ -128,18 +214,103 @@ func DictDelete(args []string) {
 	fmt.Printf("deleted: %q\n", phrase)
 }
 
-func DictList() {
+func DictList(args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print entries as JSON")
+	filter := fs.String("filter", "", "only show entries whose phrase or value contains this text")
+	_ = fs.Parse(args)
+
 	d := internal.LoadDictionary()
-	entries := d.List()
+	entries := filterEntries(d.Entries(), *filter)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(entries)
+		return
+	}
+
 	if len(entries) == 0 {
-		fmt.Println("dictionary is empty")
+		if *filter != "" {
+			fmt.Printf("no entries match %q\n", *filter)
+		} else {
+			fmt.Println("dictionary is empty")
+		}
 		return
 	}
-	for phrase, replacement := range entries {
-		fmt.Printf("  %q → %q\n", phrase, replacement)
+	inCommands := false
+	for _, e := range entries {
+		if e.Command && !inCommands {
+			fmt.Println("commands:")
+			inCommands = true
+		}
+		value := strconv.Quote(e.Value)
+		if e.Command {
+			value = e.Value
+		}
+		fmt.Printf("  %-9s %q → %s\n", "["+e.Source+"]", e.Phrase, value)
 	}
 }
 
+func DictExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "", "toml, json or csv (default: from file extension, toml for stdout)")
+	files := parseInterspersed(fs, args)
+	if len(files) > 1 {
+		fmt.Fprintln(os.Stderr, "usage: golos export [--format toml|json|csv] [file]")
+		os.Exit(1)
+	}
+
+	var path string
+	if len(files) == 1 {
+		path = files[0]
+	}
+	if *format == "" {
+		*format = internal.FormatFromPath(path)
+	}
+
+	d := internal.LoadDictionary()
+	if path == "" {
+		if err := d.Export(os.Stdout, *format); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := d.Export(f, *format); err != nil {
+		_ = f.Close()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := f.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("exported %d entries to %s\n", len(d.List())+len(d.CommandList()), path)
+}
+
+// filterEntries keeps entries whose phrase or value contains filter,
+// ignoring case. An empty filter keeps everything.
+func filterEntries(entries []internal.Entry, filter string) []internal.Entry {
+	if filter == "" {
+		return entries
+	}
+	filter = strings.ToLower(filter)
+	out := entries[:0]
+	for _, e := range entries {
+		if strings.Contains(strings.ToLower(e.Phrase), filter) || strings.Contains(strings.ToLower(e.Value), filter) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
 func Setup() {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -292,6 +463,7 @@ func runForeground() {
 		os.Exit(1)
 	}
 	defer func() { _ = portaudio.Terminate() }()
+	defer app.Close()
 
 	// Write PID file so `golos stop` works in both modes
 	writePID(os.Getpid())
@@ -309,13 +481,27 @@ func runForeground() {
 
 	fmt.Println("golos — speech-to-text for Claude Code")
 	fmt.Printf("  Output:  %s\n", app.Config.OutputMode)
-	fmt.Printf("  Hotkey:  %s\n", app.Config.Hotkey)
+	for _, b := range app.Bindings {
+		fmt.Printf("  Hotkey:  %s\n", b)
+	}
+	if app.PreRoll > 0 {
+		fmt.Printf("  Mic:     always open, %s pre-roll\n", app.PreRoll)
+	}
 	fmt.Printf("  Model:   Deepgram Nova-3\n")
 	fmt.Println()
 	fmt.Println("Ready — hold hotkey to speak")
 
+	go internal.RunTeamSync(app.Proc.Dictionary())
+
 	internal.OverlayInit(app.Config.Overlay)
-	if err := internal.ListenHotkey(app.Hotkey, app.Proc.Start, app.Proc.Stop); err != nil {
+	internal.OverlayStandby(app.PreRoll > 0)
+	quit := make(chan struct{})
+	defer close(quit)
+	go app.Proc.WatchStandby(internal.OverlayVisible, quit)
+	start := func(i int) { app.Proc.StartBinding(app.Bindings[i]) }
+	stop := func(i int) { app.Proc.StopBinding(app.Bindings[i]) }
+	cancel := func(int) { app.Proc.Cancel() }
+	if err := internal.ListenHotkeys(app.Hotkeys, app.Cancel, app.Ignore, start, stop, cancel); err != nil {
 		fmt.Fprintf(os.Stderr, "Hotkey error: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/basilysf1709/golos/internal"
+)
+
+// Correct opens what the provider heard in the last delivered session in
+// $EDITOR and offers the words that were changed as dictionary entries.
+// The raw transcript is edited rather than the delivered text so the
+// entries match what the dictionary will see next time. Substitutions whose
+// misheard phrase recurs in the history are proposed by default.
+func Correct() {
+	last, err := internal.LastDelivered()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	edited, err := editText(last.Raw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	d := internal.LoadDictionary()
+	var subs []internal.Substitution
+	seen := map[string]bool{}
+	for _, s := range internal.WordDiff(last.Raw, edited) {
+		if seen[s.From] || s.From == "" || s.To == "" {
+			continue
+		}
+		seen[s.From] = true
+		subs = append(subs, s)
+	}
+	if len(subs) == 0 {
+		fmt.Println("no substitutions to learn")
+		return
+	}
+
+	history, _ := internal.LoadHistory()
+	var texts []string
+	for _, e := range history {
+		if !e.Cancelled {
+			texts = append(texts, e.Raw)
+		}
+	}
+	counts := make(map[string]int, len(subs))
+	for _, s := range subs {
+		counts[s.From] = internal.CountPhrase(texts, s.From)
+	}
+	sort.SliceStable(subs, func(i, j int) bool { return counts[subs[i].From] > counts[subs[j].From] })
+
+	reader := bufio.NewReader(os.Stdin)
+	added := 0
+	for _, s := range subs {
+		recurring := counts[s.From] > 1
+		prompt := "[y/N]"
+		if recurring {
+			prompt = "[Y/n]"
+		}
+		fmt.Printf("%q → %q (heard in %d sessions) add? %s: ", s.From, s.To, counts[s.From], prompt)
+
+		answer, _ := reader.ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		accept := answer == "y" || answer == "yes" || (answer == "" && recurring)
+		if !accept {
+			continue
+		}
+		if err := d.Add(s.From, s.To); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		added++
+	}
+	fmt.Printf("added %d entries\n", added)
+}
+
+// editText lets the user edit text in $VISUAL or $EDITOR (default vi) and
+// returns the result.
+func editText(text string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	f, err := os.CreateTemp("", "golos-correct-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	if _, err := f.WriteString(text + "\n"); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], f.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running %s: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	edited := strings.TrimSpace(string(data))
+	if edited == "" {
+		return "", errors.New("transcript is empty, nothing to learn")
+	}
+	return edited, nil
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/basilysf1709/golos/internal"
+)
+
+// Dict dispatches `golos dict` subcommands.
+func Dict(args []string) {
+	if len(args) == 0 || args[0] != "sync" {
+		fmt.Fprintln(os.Stderr, "usage: golos dict sync [--file path] [--every 1h] [--off] [git-url|path|url]")
+		os.Exit(1)
+	}
+	DictSync(args[1:])
+}
+
+// DictSync configures the team dictionary source and syncs it now. Without
+// a source it re-syncs from the configured one.
+func DictSync(args []string) {
+	fs := flag.NewFlagSet("dict sync", flag.ExitOnError)
+	file := fs.String("file", "", "dictionary path inside the repository or directory (default: dictionary.toml or .golos/dictionary.toml)")
+	every := fs.String("every", "", "re-sync interval while golos runs, e.g. 30m (0 disables; default 1h)")
+	off := fs.Bool("off", false, "stop syncing and remove the team dictionary")
+	_ = fs.Parse(args)
+
+	if *off {
+		if err := internal.RemoveTeamSync(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("team dictionary removed")
+		return
+	}
+
+	t, err := internal.LoadTeamSync()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if fs.NArg() > 0 {
+		t = &internal.TeamSync{Source: fs.Arg(0), Interval: "1h"}
+	}
+	if t == nil {
+		fmt.Fprintln(os.Stderr, "no team dictionary configured\n  usage: golos dict sync <git-url|path|url>")
+		os.Exit(1)
+	}
+	if *file != "" {
+		t.File = *file
+	}
+	if *every != "" {
+		if _, err := time.ParseDuration(*every); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --every: %v\n", err)
+			os.Exit(1)
+		}
+		t.Interval = *every
+	}
+
+	count, err := t.Sync()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("synced %d team words from %s\n", count, t.Source)
+	if interval := t.Period(); interval > 0 {
+		fmt.Printf("  re-syncing every %s while golos runs\n", interval)
+	}
+}
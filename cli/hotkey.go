@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/basilysf1709/golos/internal"
+)
+
+// Hotkey lists the configured hotkeys, or with "test" prints key events
+// as they arrive, to find key names, and reports when a hotkey fires.
+func Hotkey(args []string) {
+	cfg, err := internal.ReadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	bindings, err := cfg.HotkeyBindings()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(args) == 0 || args[0] == "list" {
+		for _, b := range bindings {
+			spec, err := internal.ParseHotkey(b.Hotkey)
+			if err != nil {
+				fmt.Printf("  %-24s %v\n", b, err)
+				continue
+			}
+			fmt.Printf("  %-24s %s\n", b, spec)
+		}
+		return
+	}
+	if args[0] != "test" {
+		fmt.Fprintln(os.Stderr, "usage: golos hotkey [list | test [hotkey]]")
+		os.Exit(1)
+	}
+
+	// Test the hotkey given, or else the configured ones.
+	var specs []internal.HotkeySpec
+	if len(args) > 1 {
+		spec, err := internal.ParseHotkey(strings.Join(args[1:], " "))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		specs = append(specs, spec)
+	} else {
+		for _, b := range bindings {
+			spec, err := internal.ParseHotkey(b.Hotkey)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			specs = append(specs, spec)
+		}
+	}
+
+	cancelKey, err := cfg.CancelChord()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var ignore internal.Chord
+	if cfg.Polish.Modifier != "" {
+		if ignore, err = internal.ParseModifiers(cfg.Polish.Modifier); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: polish modifier: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if !internal.CheckAccessibility() {
+		fmt.Fprintln(os.Stderr, "Accessibility permission required: System Settings → Privacy & Security → Accessibility")
+		os.Exit(1)
+	}
+
+	fmt.Println("Press keys to see their names; Ctrl+C to quit.")
+	for _, spec := range specs {
+		fmt.Printf("  Hotkey:  %s\n", spec)
+	}
+	fmt.Println()
+
+	internal.WatchKeys(func(ev internal.KeyEvent) {
+		fmt.Printf("  %s\n", ev)
+	})
+	down := func(i int) { fmt.Printf("▶ %s pressed\n", specs[i]) }
+	up := func(i int) { fmt.Printf("■ %s released\n", specs[i]) }
+	cancel := func(i int) { fmt.Printf("✖ %s cancelled\n", specs[i]) }
+	if err := internal.ListenHotkeys(specs, cancelKey, ignore, down, up, cancel); err != nil {
+		fmt.Fprintf(os.Stderr, "Hotkey error: %v\n", err)
+		os.Exit(1)
+	}
+}
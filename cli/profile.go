@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/basilysf1709/golos/internal"
+)
+
+// Profile lists the configured per-application profiles, or with "test"
+// reports which profile would apply to an application.
+func Profile(args []string) {
+	cfg, err := internal.ReadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(args) == 0 || args[0] == "list" {
+		listProfiles(cfg)
+		return
+	}
+	if args[0] != "test" {
+		fmt.Fprintln(os.Stderr, "usage: golos profile [list | test [app]]")
+		os.Exit(1)
+	}
+
+	var app internal.AppInfo
+	if len(args) > 1 {
+		name := strings.Join(args[1:], " ")
+		app = internal.AppInfo{Name: name, ID: name}
+	} else {
+		// The terminal running this command is frontmost, so give the user
+		// a moment to switch to the app they want to test.
+		for i := 3; i > 0; i-- {
+			fmt.Printf("\r\033[KSwitch to the app to test... %d", i)
+			time.Sleep(time.Second)
+		}
+		fmt.Print("\r\033[K")
+		app, err = internal.FrontmostApp()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("App:     %s\n", app)
+	name, prof := cfg.ProfileFor(app)
+	if prof == nil {
+		fmt.Printf("Profile: none (output %s)\n", cfg.OutputMode)
+		return
+	}
+	output := prof.OutputMode
+	if output == "" {
+		output = cfg.OutputMode
+	}
+	fmt.Printf("Profile: %s (output %s, %d words, %d commands)\n", name, output, len(prof.Words), len(prof.Commands))
+}
+
+func listProfiles(cfg *internal.Config) {
+	if len(cfg.Profiles) == 0 {
+		fmt.Println("no profiles configured")
+		return
+	}
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := cfg.Profiles[name]
+		output := p.OutputMode
+		if output == "" {
+			output = "(default)"
+		}
+		fmt.Printf("  %s\n", name)
+		fmt.Printf("    apps:     %s\n", strings.Join(p.Apps, ", "))
+		fmt.Printf("    output:   %s\n", output)
+		if p.Newline != "" {
+			fmt.Printf("    newline:  %s\n", p.Newline)
+		}
+		fmt.Printf("    words:    %d\n", len(p.Words))
+		fmt.Printf("    commands: %d\n", len(p.Commands))
+	}
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/basilysf1709/golos/internal"
+	"github.com/basilysf1709/golos/processor"
+)
+
+// Replay re-runs a recorded session through the current provider,
+// dictionary and pipeline and prints the new transcript next to the one
+// recorded, or with "list" lists the recorded sessions. The exec and
+// polish stages only run with --pipeline.
+func Replay(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	full := fs.Bool("pipeline", false, "also run the exec and polish stages")
+	args = parseInterspersed(fs, args)
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "usage: golos replay [--pipeline] [list | last | <session>]")
+		os.Exit(1)
+	}
+	if len(args) == 1 && args[0] == "list" {
+		names, err := internal.ListRecordings()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return
+	}
+
+	session := "last"
+	if len(args) == 1 {
+		session = args[0]
+	}
+	path, err := internal.FindRecording(session)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	samples, rate, err := internal.ReadWAV(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	log, err := internal.LoadSessionLog(path)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.RecordAudio = false
+	proc, err := processor.New(cfg, &internal.StdoutMode{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Replaying %s (%.1fs at %d Hz)\n", path, float64(len(samples))/float64(rate), rate)
+	raw, text, err := proc.Replay(samples, rate, log.Language, *full)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("  Recorded:  %s\n", log.Transcript())
+	fmt.Printf("  Now:       %s\n", raw)
+	fmt.Printf("  Delivered: %s\n", text)
+}
@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ActionKind identifies what a voice command does.
+type ActionKind string
+
+const (
+	ActionEnter  ActionKind = "enter"  // press Return after delivery
+	ActionKey    ActionKind = "key"    // send a key chord, e.g. "key ctrl+c"
+	ActionUndo   ActionKind = "undo"   // delete the last delivered transcript
+	ActionOutput ActionKind = "output" // switch output mode, e.g. "output stdout"
+	ActionShell  ActionKind = "shell"  // run a shell command
+)
+
+// Action is a parsed voice command from the [commands] table.
+type Action struct {
+	Kind  ActionKind
+	Arg   string
+	Chord Chord // set for ActionKey
+}
+
+// ParseAction parses a command spec of the form "<kind> [argument]".
+func ParseAction(spec string) (Action, error) {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(spec), " ")
+	a := Action{Kind: ActionKind(strings.ToLower(kind)), Arg: strings.TrimSpace(arg)}
+
+	switch a.Kind {
+	case ActionEnter, ActionUndo:
+		if a.Arg != "" {
+			return Action{}, fmt.Errorf("%s takes no argument", a.Kind)
+		}
+	case ActionKey:
+		ch, err := ParseChord(a.Arg)
+		if err != nil {
+			return Action{}, err
+		}
+		a.Chord = ch
+	case ActionOutput, ActionShell:
+		if a.Arg == "" {
+			return Action{}, fmt.Errorf("%s requires an argument", a.Kind)
+		}
+	default:
+		return Action{}, fmt.Errorf("unknown action %q (supported: enter, key, undo, output, shell)", kind)
+	}
+	return a, nil
+}
+
+// BeforeDelivery reports whether the action must run before the remaining
+// text is delivered: undo and output switches affect where it goes, while
+// key presses and shell commands follow it.
+func (a Action) BeforeDelivery() bool {
+	return a.Kind == ActionUndo || a.Kind == ActionOutput
+}
@@ -0,0 +1,61 @@
+package internal
+
+import "testing"
+
+func TestParseAction(t *testing.T) {
+	tests := []struct {
+		spec string
+		kind ActionKind
+		arg  string
+	}{
+		{"enter", ActionEnter, ""},
+		{"undo", ActionUndo, ""},
+		{"key ctrl+c", ActionKey, "ctrl+c"},
+		{"output stdout", ActionOutput, "stdout"},
+		{"shell make test -j4", ActionShell, "make test -j4"},
+		{"  KEY   escape ", ActionKey, "escape"},
+	}
+	for _, tt := range tests {
+		a, err := ParseAction(tt.spec)
+		if err != nil {
+			t.Errorf("ParseAction(%q): %v", tt.spec, err)
+			continue
+		}
+		if a.Kind != tt.kind || a.Arg != tt.arg {
+			t.Errorf("ParseAction(%q) = {%s %q}, want {%s %q}", tt.spec, a.Kind, a.Arg, tt.kind, tt.arg)
+		}
+	}
+}
+
+func TestParseActionKeySetsChord(t *testing.T) {
+	a, err := ParseAction("key ctrl+c")
+	if err != nil {
+		t.Fatalf("ParseAction: %v", err)
+	}
+	if !a.Chord.Ctrl || a.Chord.Key != "c" {
+		t.Errorf("Chord = %+v, want ctrl+c", a.Chord)
+	}
+}
+
+func TestParseActionErrors(t *testing.T) {
+	for _, spec := range []string{"", "launch rockets", "enter now", "key", "key hyper+x", "output", "shell"} {
+		if _, err := ParseAction(spec); err == nil {
+			t.Errorf("ParseAction(%q): expected error", spec)
+		}
+	}
+}
+
+func TestBeforeDelivery(t *testing.T) {
+	before := map[ActionKind]bool{
+		ActionEnter:  false,
+		ActionKey:    false,
+		ActionShell:  false,
+		ActionUndo:   true,
+		ActionOutput: true,
+	}
+	for kind, want := range before {
+		if got := (Action{Kind: kind}).BeforeDelivery(); got != want {
+			t.Errorf("%s.BeforeDelivery() = %v, want %v", kind, got, want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AppInfo identifies the frontmost application. ID is the bundle identifier
+// on macOS and the WM_CLASS instance on Linux.
+type AppInfo struct {
+	Name string
+	ID   string
+}
+
+func (a AppInfo) String() string {
+	if a.ID == "" {
+		return a.Name
+	}
+	return fmt.Sprintf("%s (%s)", a.Name, a.ID)
+}
+
+// Profile overrides the dictionary and output mode while one of its
+// applications is frontmost.
+type Profile struct {
+	Apps       []string          `toml:"apps"`
+	OutputMode string            `toml:"output_mode"`
+	Newline    string            `toml:"newline"` // key sent instead of each line break, e.g. "shift+enter"
+	Words      map[string]string `toml:"words"`
+	Commands   map[string]string `toml:"commands"`
+}
+
+// Matches reports whether the profile applies to app, comparing each entry
+// of Apps to the app's name and ID without regard to case.
+func (p Profile) Matches(app AppInfo) bool {
+	for _, a := range p.Apps {
+		if strings.EqualFold(a, app.Name) || (app.ID != "" && strings.EqualFold(a, app.ID)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProfileFor returns the profile that applies to app. When several match,
+// the first by name wins. It returns "" and nil if none applies.
+func (c *Config) ProfileFor(app AppInfo) (string, *Profile) {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := c.Profiles[name]
+		if p.Matches(app) {
+			return name, &p
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,36 @@
+package internal
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Cocoa
+#import <Cocoa/Cocoa.h>
+#include <stdlib.h>
+#include <string.h>
+
+static void frontmostApp(char **name, char **bundleID) {
+    @autoreleasepool {
+        NSRunningApplication *app = [[NSWorkspace sharedWorkspace] frontmostApplication];
+        *name = app.localizedName ? strdup(app.localizedName.UTF8String) : NULL;
+        *bundleID = app.bundleIdentifier ? strdup(app.bundleIdentifier.UTF8String) : NULL;
+    }
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// FrontmostApp returns the application that currently has focus.
+func FrontmostApp() (AppInfo, error) {
+	var name, bundleID *C.char
+	C.frontmostApp(&name, &bundleID)
+	defer C.free(unsafe.Pointer(name))
+	defer C.free(unsafe.Pointer(bundleID))
+
+	if name == nil && bundleID == nil {
+		return AppInfo{}, fmt.Errorf("no frontmost application")
+	}
+	return AppInfo{Name: C.GoString(name), ID: C.GoString(bundleID)}, nil
+}
@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// FrontmostApp returns the X11 window named by the root window's
+// _NET_ACTIVE_WINDOW property, identified by its WM_CLASS. It needs xprop.
+func FrontmostApp() (AppInfo, error) {
+	out, err := exec.Command("xprop", "-root", "_NET_ACTIVE_WINDOW").Output()
+	if err != nil {
+		return AppInfo{}, fmt.Errorf("xprop: %w", err)
+	}
+	id, err := parseActiveWindow(string(out))
+	if err != nil {
+		return AppInfo{}, err
+	}
+
+	out, err = exec.Command("xprop", "-id", id, "WM_CLASS").Output()
+	if err != nil {
+		return AppInfo{}, fmt.Errorf("xprop: %w", err)
+	}
+	return parseWMClass(string(out))
+}
+
+// parseActiveWindow extracts the window id from
+// "_NET_ACTIVE_WINDOW(WINDOW): window id # 0x3a00007".
+func parseActiveWindow(out string) (string, error) {
+	fields := strings.Fields(out)
+	if len(fields) == 0 || !strings.HasPrefix(fields[len(fields)-1], "0x") {
+		return "", fmt.Errorf("no active window")
+	}
+	id := strings.TrimSuffix(fields[len(fields)-1], ",")
+	if id == "0x0" {
+		return "", fmt.Errorf("no active window")
+	}
+	return id, nil
+}
+
+// parseWMClass parses `WM_CLASS(STRING) = "slack", "Slack"` into the class
+// name and instance.
+func parseWMClass(out string) (AppInfo, error) {
+	_, values, ok := strings.Cut(out, "=")
+	if !ok {
+		return AppInfo{}, fmt.Errorf("window has no WM_CLASS")
+	}
+	var parts []string
+	for _, v := range strings.Split(values, ",") {
+		parts = append(parts, strings.Trim(strings.TrimSpace(v), `"`))
+	}
+	if len(parts) == 1 {
+		return AppInfo{Name: parts[0]}, nil
+	}
+	return AppInfo{Name: parts[1], ID: parts[0]}, nil
+}
@@ -0,0 +1,26 @@
+package internal
+
+import "testing"
+
+func TestParseActiveWindow(t *testing.T) {
+	id, err := parseActiveWindow("_NET_ACTIVE_WINDOW(WINDOW): window id # 0x3a00007\n")
+	if err != nil || id != "0x3a00007" {
+		t.Errorf("got %q, %v; want 0x3a00007", id, err)
+	}
+	if _, err := parseActiveWindow("_NET_ACTIVE_WINDOW(WINDOW): window id # 0x0\n"); err == nil {
+		t.Error("expected error for 0x0")
+	}
+}
+
+func TestParseWMClass(t *testing.T) {
+	app, err := parseWMClass(`WM_CLASS(STRING) = "slack", "Slack"` + "\n")
+	if err != nil {
+		t.Fatalf("parseWMClass: %v", err)
+	}
+	if app.Name != "Slack" || app.ID != "slack" {
+		t.Errorf("got %+v, want {Slack slack}", app)
+	}
+	if _, err := parseWMClass("WM_CLASS:  not found.\n"); err == nil {
+		t.Error("expected error without WM_CLASS")
+	}
+}
@@ -0,0 +1,46 @@
+package internal
+
+import "testing"
+
+func TestProfileMatches(t *testing.T) {
+	p := Profile{Apps: []string{"Slack", "com.google.Chrome"}}
+	tests := []struct {
+		app  AppInfo
+		want bool
+	}{
+		{AppInfo{Name: "slack"}, true},
+		{AppInfo{Name: "Google Chrome", ID: "com.google.chrome"}, true},
+		{AppInfo{Name: "iTerm2", ID: "com.googlecode.iterm2"}, false},
+		{AppInfo{}, false},
+	}
+	for _, tt := range tests {
+		if got := p.Matches(tt.app); got != tt.want {
+			t.Errorf("Matches(%+v) = %v, want %v", tt.app, got, tt.want)
+		}
+	}
+}
+
+func TestProfileForFirstByName(t *testing.T) {
+	cfg := &Config{Profiles: map[string]Profile{
+		"chat":   {Apps: []string{"Slack"}, OutputMode: "type"},
+		"aaa":    {Apps: []string{"Slack"}, OutputMode: "clipboard"},
+		"editor": {Apps: []string{"Code"}},
+	}}
+	name, p := cfg.ProfileFor(AppInfo{Name: "Slack"})
+	if name != "aaa" || p == nil || p.OutputMode != "clipboard" {
+		t.Errorf("got %q %+v, want aaa", name, p)
+	}
+	name, p = cfg.ProfileFor(AppInfo{Name: "Terminal"})
+	if name != "" || p != nil {
+		t.Errorf("got %q %+v, want no profile", name, p)
+	}
+}
+
+func TestAppInfoString(t *testing.T) {
+	if s := (AppInfo{Name: "Slack", ID: "com.tinyspeck.slackmacgap"}).String(); s != "Slack (com.tinyspeck.slackmacgap)" {
+		t.Errorf("String() = %q", s)
+	}
+	if s := (AppInfo{Name: "Slack"}).String(); s != "Slack" {
+		t.Errorf("String() = %q", s)
+	}
+}
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Binding maps a hotkey to settings for the sessions it starts. Empty
+// fields fall back to the global config.
+type Binding struct {
+	Hotkey     string `toml:"hotkey"`
+	Language   string `toml:"language"`
+	OutputMode string `toml:"output_mode"`
+	Polish     bool   `toml:"polish"` // polish every session started with this hotkey
+}
+
+// HotkeyBindings returns the global hotkey as a binding with no overrides,
+// followed by the configured [[bindings]]. The global hotkey is left out
+// if set to "none". Each hotkey may only be bound once.
+func (c *Config) HotkeyBindings() ([]Binding, error) {
+	var bindings []Binding
+	if c.Hotkey != "" && c.Hotkey != "none" {
+		bindings = append(bindings, Binding{Hotkey: c.Hotkey})
+	}
+	bindings = append(bindings, c.Bindings...)
+	if len(bindings) == 0 {
+		return nil, fmt.Errorf("no hotkey configured")
+	}
+
+	seen := map[string]bool{}
+	for _, b := range bindings {
+		if b.Hotkey == "" {
+			return nil, fmt.Errorf("binding without a hotkey")
+		}
+		if seen[b.Hotkey] {
+			return nil, fmt.Errorf("hotkey %s is bound more than once", b.Hotkey)
+		}
+		seen[b.Hotkey] = true
+	}
+	return bindings, nil
+}
+
+// CancelChord parses the cancel key. It has no Key when cancelling is off.
+func (c *Config) CancelChord() (Chord, error) {
+	if c.CancelKey == "" || c.CancelKey == "none" {
+		return Chord{}, nil
+	}
+	ch, err := ParseChord(c.CancelKey)
+	if err != nil {
+		return Chord{}, fmt.Errorf("cancel_key: %w", err)
+	}
+	return ch, nil
+}
+
+// String describes the binding for the startup banner.
+func (b Binding) String() string {
+	var opts []string
+	if b.Language != "" {
+		opts = append(opts, b.Language)
+	}
+	if b.OutputMode != "" {
+		opts = append(opts, b.OutputMode)
+	}
+	if b.Polish {
+		opts = append(opts, "polish")
+	}
+	if len(opts) == 0 {
+		return b.Hotkey
+	}
+	return b.Hotkey + " (" + strings.Join(opts, ", ") + ")"
+}
@@ -0,0 +1,87 @@
+package internal
+
+import "testing"
+
+func TestHotkeyBindings(t *testing.T) {
+	cfg := &Config{Hotkey: "right_option", Bindings: []Binding{
+		{Hotkey: "right_command", Language: "de"},
+		{Hotkey: "f19", OutputMode: "type", Polish: true},
+	}}
+	got, err := cfg.HotkeyBindings()
+	if err != nil {
+		t.Fatalf("HotkeyBindings: %v", err)
+	}
+	want := []string{"right_option", "right_command", "f19"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d bindings, want %d", len(got), len(want))
+	}
+	for i, b := range got {
+		if b.Hotkey != want[i] {
+			t.Errorf("binding %d = %q, want %q", i, b.Hotkey, want[i])
+		}
+	}
+	if got[0].Language != "" || got[0].OutputMode != "" || got[0].Polish {
+		t.Errorf("global binding has overrides: %+v", got[0])
+	}
+}
+
+func TestHotkeyBindingsWithoutGlobal(t *testing.T) {
+	cfg := &Config{Hotkey: "none", Bindings: []Binding{{Hotkey: "f19"}}}
+	got, err := cfg.HotkeyBindings()
+	if err != nil {
+		t.Fatalf("HotkeyBindings: %v", err)
+	}
+	if len(got) != 1 || got[0].Hotkey != "f19" {
+		t.Errorf("got %+v, want only f19", got)
+	}
+}
+
+func TestHotkeyBindingsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"none", Config{Hotkey: "none"}},
+		{"duplicate", Config{Hotkey: "f19", Bindings: []Binding{{Hotkey: "f19", Language: "de"}}}},
+		{"empty", Config{Hotkey: "f19", Bindings: []Binding{{Language: "de"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.cfg.HotkeyBindings(); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestBindingString(t *testing.T) {
+	tests := []struct {
+		b    Binding
+		want string
+	}{
+		{Binding{Hotkey: "right_option"}, "right_option"},
+		{Binding{Hotkey: "right_command", Language: "de"}, "right_command (de)"},
+		{Binding{Hotkey: "f19", OutputMode: "clipboard", Polish: true}, "f19 (clipboard, polish)"},
+	}
+	for _, tt := range tests {
+		if got := tt.b.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestCancelChord(t *testing.T) {
+	for in, want := range map[string]string{"escape": "escape", "ctrl+g": "ctrl+g", "none": "", "": ""} {
+		ch, err := (&Config{CancelKey: in}).CancelChord()
+		if err != nil {
+			t.Errorf("CancelChord(%q): %v", in, err)
+			continue
+		}
+		if ch.Key != "" && ch.String() != want || ch.Key == "" && want != "" {
+			t.Errorf("CancelChord(%q) = %q, want %q", in, ch, want)
+		}
+	}
+	if _, err := (&Config{CancelKey: "nosuchkey"}).CancelChord(); err == nil {
+		t.Error("expected an error for an unknown key")
+	}
+}
@@ -8,25 +8,84 @@ import (
 )
 
 const (
-	SampleRate   = 16000
+	SampleRate   = 16000 // default rate sessions are captured and streamed at
 	Channels     = 1
 	FrameDurMs   = 20
 	FrameSamples = SampleRate * FrameDurMs / 1000 // 320 samples per 20ms frame
 )
 
+// FrameSize returns the samples in a 20ms frame at rate.
+func FrameSize(rate int) int {
+	return rate * FrameDurMs / 1000
+}
+
+// CheckSampleRate reports whether sessions can run at rate; the VAD
+// only handles these.
+func CheckSampleRate(rate int) error {
+	switch rate {
+	case 8000, 16000, 32000, 48000:
+		return nil
+	}
+	return fmt.Errorf("sample_rate %d: must be 8000, 16000, 32000 or 48000", rate)
+}
+
 type Capture struct {
 	stream *portaudio.Stream
+	rate   int // rate of the delivered frames
 	frames chan []int16
 	stop   chan struct{}
 	wg     sync.WaitGroup
+	gaps   gapLog
+}
+
+// Gap is a run of frames dropped because the consumer fell behind.
+type Gap struct {
+	At     int // index of the first dropped frame in the session
+	Frames int
+}
+
+// gapLog counts frames as they are delivered or dropped, and records
+// where the drops were.
+type gapLog struct {
+	mu   sync.Mutex
+	next int // index of the next frame
+	gaps []Gap
+}
+
+func (l *gapLog) sent() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.next++
+}
+
+func (l *gapLog) dropped() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n := len(l.gaps); n > 0 && l.gaps[n-1].At+l.gaps[n-1].Frames == l.next {
+		l.gaps[n-1].Frames++
+	} else {
+		l.gaps = append(l.gaps, Gap{At: l.next, Frames: 1})
+	}
+	l.next++
+}
+
+func (l *gapLog) list() []Gap {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Gap(nil), l.gaps...)
 }
 
-// NewCapture creates a mic capture that delivers 20ms PCM16 frames on a buffered channel.
-func NewCapture(bufferSize int) (*Capture, error) {
+// NewCapture creates a mic capture that delivers 20ms PCM16 frames at
+// rate on a buffered channel. A rate of 0 means SampleRate.
+func NewCapture(bufferSize, rate int) (*Capture, error) {
 	if bufferSize <= 0 {
 		bufferSize = 64
 	}
+	if rate <= 0 {
+		rate = SampleRate
+	}
 	c := &Capture{
+		rate:   rate,
 		frames: make(chan []int16, bufferSize),
 		stop:   make(chan struct{}),
 	}
@@ -38,16 +97,37 @@ func (c *Capture) Frames() <-chan []int16 {
 	return c.frames
 }
 
-// Start opens the default mic and begins capturing.
+// Start opens the default mic at its native rate and begins capturing,
+// resampling to the capture's rate if they differ. Many USB mics only
+// run at 44.1 or 48 kHz.
 func (c *Capture) Start() error {
-	buf := make([]int16, FrameSamples)
+	dev, err := portaudio.DefaultInputDevice()
+	if err != nil {
+		return fmt.Errorf("find mic: %w", err)
+	}
+	native := int(dev.DefaultSampleRate)
+	if native <= 0 {
+		native = c.rate
+	}
+	buf := make([]int16, FrameSize(native))
 
-	stream, err := portaudio.OpenDefaultStream(Channels, 0, float64(SampleRate), FrameSamples, buf)
+	params := portaudio.LowLatencyParameters(dev, nil)
+	params.Input.Channels = Channels
+	params.SampleRate = float64(native)
+	params.FramesPerBuffer = len(buf)
+	stream, err := portaudio.OpenStream(params, buf)
 	if err != nil {
 		return fmt.Errorf("open mic stream: %w", err)
 	}
 	c.stream = stream
 
+	var rs *Resampler
+	if native != c.rate {
+		rs = NewResampler(native, c.rate)
+	}
+	size := FrameSize(c.rate)
+	var pending []int16
+
 	if err := stream.Start(); err != nil {
 		_ = stream.Close()
 		return fmt.Errorf("start mic stream: %w", err)
@@ -66,12 +146,14 @@ func (c *Capture) Start() error {
 				// Stream closed or error — exit
 				return
 			}
-			frame := make([]int16, FrameSamples)
-			copy(frame, buf)
-			select {
-			case c.frames <- frame:
-			default:
-				// Drop frame if consumer is too slow
+			if rs == nil {
+				c.deliver(append([]int16(nil), buf...))
+				continue
+			}
+			pending = append(pending, rs.Process(buf)...)
+			for len(pending) >= size {
+				c.deliver(append([]int16(nil), pending[:size]...))
+				pending = pending[size:]
 			}
 		}
 	}()
@@ -79,6 +161,21 @@ func (c *Capture) Start() error {
 	return nil
 }
 
+func (c *Capture) deliver(frame []int16) {
+	select {
+	case c.frames <- frame:
+		c.gaps.sent()
+	default:
+		// Drop frame if consumer is too slow
+		c.gaps.dropped()
+	}
+}
+
+// Gaps returns the runs of frames dropped so far.
+func (c *Capture) Gaps() []Gap {
+	return c.gaps.list()
+}
+
 // Stop halts capture and releases resources.
 func (c *Capture) Stop() {
 	close(c.stop)
@@ -5,7 +5,7 @@ import (
 )
 
 func TestNewCaptureDefaultBuffer(t *testing.T) {
-	c, err := NewCapture(0)
+	c, err := NewCapture(0, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -15,7 +15,7 @@ func TestNewCaptureDefaultBuffer(t *testing.T) {
 }
 
 func TestNewCaptureNegativeBuffer(t *testing.T) {
-	c, err := NewCapture(-10)
+	c, err := NewCapture(-10, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -25,7 +25,7 @@ func TestNewCaptureNegativeBuffer(t *testing.T) {
 }
 
 func TestNewCaptureCustomBuffer(t *testing.T) {
-	c, err := NewCapture(128)
+	c, err := NewCapture(128, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -35,7 +35,7 @@ func TestNewCaptureCustomBuffer(t *testing.T) {
 }
 
 func TestFramesReturnsSameChannel(t *testing.T) {
-	c, _ := NewCapture(8)
+	c, _ := NewCapture(8, 0)
 	ch := c.Frames()
 
 	// Send a frame directly on the internal channel
@@ -50,7 +50,7 @@ func TestFramesReturnsSameChannel(t *testing.T) {
 }
 
 func TestFramesChannelIsReceiveOnly(t *testing.T) {
-	c, _ := NewCapture(8)
+	c, _ := NewCapture(8, 0)
 	ch := c.Frames()
 
 	// Type assertion: Frames() returns <-chan, not chan
@@ -60,7 +60,7 @@ func TestFramesChannelIsReceiveOnly(t *testing.T) {
 }
 
 func TestStopClosesFramesChannel(t *testing.T) {
-	c, _ := NewCapture(8)
+	c, _ := NewCapture(8, 0)
 
 	// Stop without Start — stream is nil, should still close channels cleanly
 	c.Stop()
@@ -73,7 +73,7 @@ func TestStopClosesFramesChannel(t *testing.T) {
 }
 
 func TestFrameDropWhenBufferFull(t *testing.T) {
-	c, _ := NewCapture(2) // tiny buffer
+	c, _ := NewCapture(2, 0) // tiny buffer
 
 	// Fill the buffer
 	c.frames <- make([]int16, FrameSamples)
@@ -100,3 +100,19 @@ func TestConstants(t *testing.T) {
 		t.Errorf("FrameSamples = %d, want 320", FrameSamples)
 	}
 }
+
+func TestGapLog(t *testing.T) {
+	var l gapLog
+	for _, ok := range []bool{true, true, false, false, true, false, true} {
+		if ok {
+			l.sent()
+		} else {
+			l.dropped()
+		}
+	}
+	got := l.list()
+	want := []Gap{{At: 2, Frames: 2}, {At: 5, Frames: 1}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("gaps = %+v, want %+v", got, want)
+	}
+}
@@ -51,3 +51,9 @@ func (c *ClipboardMode) Deliver(text string) error {
 
 	return nil
 }
+
+// Submit presses Return once the paste has landed.
+func (c *ClipboardMode) Submit() error {
+	time.Sleep(50 * time.Millisecond)
+	return SendChord(Chord{Key: "return", Code: keyCodes["return"]})
+}
@@ -14,17 +14,112 @@ type Config struct {
 	Hotkey         string `toml:"hotkey"`
 	OutputMode     string `toml:"output_mode"`
 	SampleRate     int    `toml:"sample_rate"`
-	Language       string `toml:"language"`
+	Language       string `toml:"language"` // a language tag, or "auto" to detect it
 	Overlay        bool   `toml:"overlay"`
+	TmuxTarget     string `toml:"tmux_target"`
+
+	// CancelKey discards the session when pressed while the hotkey is
+	// held, e.g. "escape". "none" turns cancelling off.
+	CancelKey string `toml:"cancel_key"`
+
+	// MinHold is how long the hotkey must be held for a session to count,
+	// e.g. "300ms"; shorter taps are dropped without connecting. Empty
+	// (the default) keeps every session.
+	MinHold string `toml:"min_hold"`
+
+	// SkipSilence waits for the VAD to hear speech before connecting to
+	// the provider, and drops sessions in which it heard none. Off by
+	// default.
+	SkipSilence bool `toml:"skip_silence"`
+
+	// PreRoll keeps the mic open and prepends this much audio from before
+	// the hotkey to each session, e.g. "300ms". It is held in memory only
+	// and needs the overlay on.
+	PreRoll string `toml:"pre_roll"`
+
+	// Bindings add hotkeys that start sessions with their own language,
+	// output mode or polish setting.
+	Bindings []Binding `toml:"bindings"`
+
+	// Languages are the ones expected with auto detection, e.g. ["en",
+	// "es"]. Deepgram isn't told about them; a result detected as another
+	// language keeps its label and is marked as unlisted.
+	Languages []string `toml:"languages"`
+
+	// ProjectDir pins where to look for a project's .golos/dictionary.toml
+	// instead of detecting the working directory.
+	ProjectDir string `toml:"project_dir"`
+
+	// SubmitTriggers are phrases that, when they end an utterance, are
+	// stripped and make the output mode press Return after delivery.
+	SubmitTriggers []string `toml:"submit_triggers"`
+
+	// Profiles select a dictionary overlay and output mode by the
+	// frontmost application, keyed by profile name.
+	Profiles map[string]Profile `toml:"profiles"`
+
+	// Format toggles the spoken-form transforms applied after the
+	// dictionary, such as casing commands and file paths.
+	Format FormatConfig `toml:"format"`
+
+	// Filler removes filler words and repeated words before the
+	// dictionary runs.
+	Filler FillerConfig `toml:"filler"`
+
+	// VAD selects and tunes the voice activity detector that decides
+	// when speech starts and ends.
+	VAD VADConfig `toml:"vad"`
+
+	// Trim holds back the silence around speech instead of streaming it.
+	Trim TrimConfig `toml:"trim"`
+
+	// DSP conditions the mic audio before the VAD and the provider hear
+	// it: high-pass filter, noise suppression and gain control.
+	DSP DSPConfig `toml:"dsp"`
+
+	// Polish configures the optional LLM rewrite at the end of the
+	// pipeline.
+	Polish PolishConfig `toml:"polish"`
+
+	// Pipeline lists the post-processing stages a transcript goes
+	// through before delivery, in order. Empty means filler (if enabled),
+	// dictionary, format, polish (if configured).
+	Pipeline []StageConfig `toml:"pipeline"`
+
+	// History records each session's transcript in
+	// ~/.config/golos/history.jsonl, for `golos correct`.
+	History bool `toml:"history"`
+
+	// RecordAudio saves each session's audio as a WAV file in
+	// ~/.config/golos/recordings, with the provider's results alongside,
+	// for debugging and `golos replay`.
+	RecordAudio bool `toml:"record_audio"`
 }
 
+// LoadConfig reads the configuration and requires a Deepgram API key.
 func LoadConfig() (*Config, error) {
+	cfg, err := ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DeepgramAPIKey == "" {
+		return nil, fmt.Errorf("DEEPGRAM_API_KEY is required — run 'golos setup' to configure")
+	}
+	return cfg, nil
+}
+
+// ReadConfig layers defaults, the config file and environment variables
+// without validating them, for commands that don't need the API key.
+func ReadConfig() (*Config, error) {
 	cfg := &Config{
 		Hotkey:     "right_option",
+		CancelKey:  "escape",
 		OutputMode: "clipboard",
 		SampleRate: 16000,
 		Language:   "en-US",
 		Overlay:    true,
+		Trim:       TrimConfig{Pad: "200ms"},
+		VAD:        VADConfig{Mode: 3, Hangover: "300ms", FrameMs: 20},
 	}
 
 	// Load .env file from current directory (silent if missing)
@@ -50,9 +145,8 @@ func LoadConfig() (*Config, error) {
 	if hotkey := os.Getenv("GOLOS_HOTKEY"); hotkey != "" {
 		cfg.Hotkey = hotkey
 	}
-
-	if cfg.DeepgramAPIKey == "" {
-		return nil, fmt.Errorf("DEEPGRAM_API_KEY is required — run 'golos setup' to configure")
+	if key := os.Getenv("GOLOS_POLISH_API_KEY"); key != "" {
+		cfg.Polish.APIKey = key
 	}
 
 	return cfg, nil
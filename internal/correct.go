@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Substitution is a run of words the user replaced when correcting a
+// transcript, e.g. "cube control" → "kubectl".
+type Substitution struct {
+	From string
+	To   string
+}
+
+// WordDiff compares an original transcript with its corrected version word
+// by word and returns the replaced runs. Pure insertions and deletions are
+// not substitutions and are left out, as are changes to the punctuation
+// around words.
+func WordDiff(original, corrected string) []Substitution {
+	a := strings.Fields(original)
+	b := strings.Fields(corrected)
+	key := func(w string) string { return strings.TrimFunc(w, unicode.IsPunct) }
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if key(a[i]) == key(b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var subs []Substitution
+	var from, to []string
+	flush := func() {
+		if len(from) > 0 && len(to) > 0 {
+			subs = append(subs, Substitution{
+				From: strings.TrimFunc(strings.Join(from, " "), unicode.IsPunct),
+				To:   strings.TrimFunc(strings.Join(to, " "), unicode.IsPunct),
+			})
+		}
+		from, to = nil, nil
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && key(a[i]) == key(b[j]):
+			flush()
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			to = append(to, b[j])
+			j++
+		default:
+			from = append(from, a[i])
+			i++
+		}
+	}
+	flush()
+	return subs
+}
+
+// CountPhrase counts how many of the texts contain phrase, matched the way
+// dictionary phrases are.
+func CountPhrase(texts []string, phrase string) int {
+	want := strings.Fields(normalizePhrase(phrase))
+	if len(want) == 0 {
+		return 0
+	}
+	count := 0
+	for _, text := range texts {
+		toks := tokenize(text)
+		for i := range toks {
+			if _, ok := matchAt(toks, i, want); ok {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
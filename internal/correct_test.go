@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWordDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		original  string
+		corrected string
+		want      []Substitution
+	}{
+		{"unchanged", "deploy to prod", "deploy to prod", nil},
+		{"single word", "restart the cube cluster", "restart the kube cluster", []Substitution{{"cube", "kube"}}},
+		{"many to one", "run cube control apply", "run kubectl apply", []Substitution{{"cube control", "kubectl"}}},
+		{"casing", "push to github now", "push to GitHub now", []Substitution{{"github", "GitHub"}}},
+		{"punctuation ignored", "ship it.", "ship it", nil},
+		{"punctuation around substitution", "ask jon.", "ask John.", []Substitution{{"jon", "John"}}},
+		{"insertion only", "open file", "open the file", nil},
+		{"deletion only", "um open the file", "open the file", nil},
+		{"two substitutions", "cube is on get hub", "kube is on GitHub", []Substitution{{"cube", "kube"}, {"get hub", "GitHub"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WordDiff(tt.original, tt.corrected)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WordDiff(%q, %q) = %v, want %v", tt.original, tt.corrected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountPhrase(t *testing.T) {
+	texts := []string{"restart Cube now", "the cube, again", "a cubed thing", "nothing here"}
+	if got := CountPhrase(texts, "cube"); got != 2 {
+		t.Errorf("CountPhrase = %d, want 2", got)
+	}
+	if got := CountPhrase(texts, ""); got != 0 {
+		t.Errorf("CountPhrase(empty) = %d, want 0", got)
+	}
+}
@@ -24,23 +24,35 @@ func initSDK() {
 type DeepgramProvider struct {
 	apiKey  string
 	lang    string
+	rate    int
+	allowed []string // expected languages, spelled as configured, when lang is auto
 	results chan TranscriptResult
 	dgConn  *client.WSCallback
 	ctx     context.Context
 	cancel  context.CancelFunc
 }
 
-func NewDeepgram(apiKey, language string) *DeepgramProvider {
+// NewDeepgram returns a provider for the language. With language "auto"
+// it streams with the multilingual model and reports the detected
+// language, preferring one on allowed when several are reported.
+func NewDeepgram(apiKey, language string, allowed ...string) *DeepgramProvider {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &DeepgramProvider{
 		apiKey:  apiKey,
 		lang:    language,
+		rate:    SampleRate,
+		allowed: allowed,
 		results: make(chan TranscriptResult, 64),
 		ctx:     ctx,
 		cancel:  cancel,
 	}
 }
 
+// SetSampleRate sets the rate of the audio that will be written, in Hz.
+func (d *DeepgramProvider) SetSampleRate(rate int) {
+	d.rate = rate
+}
+
 func (d *DeepgramProvider) Connect() error {
 	initSDK()
 
@@ -51,13 +63,18 @@ func (d *DeepgramProvider) Connect() error {
 		cOptions.APIKey = d.apiKey
 	}
 
+	lang := d.lang
+	if IsAutoLanguage(lang) {
+		lang = deepgramMulti
+	}
+
 	tOptions := &interfaces.LiveTranscriptionOptions{
 		Model:          "nova-3",
-		Language:       d.lang,
+		Language:       lang,
 		Punctuate:      true,
 		Encoding:       "linear16",
 		Channels:       1,
-		SampleRate:     16000,
+		SampleRate:     d.rate,
 		SmartFormat:    true,
 		InterimResults: true,
 		VadEvents:      true,
@@ -65,7 +82,7 @@ func (d *DeepgramProvider) Connect() error {
 		UtteranceEndMs: "1000",
 	}
 
-	cb := &deepgramCallback{results: d.results}
+	cb := &deepgramCallback{results: d.results, lang: d.lang, allowed: d.allowed}
 
 	conn, err := client.NewWSUsingCallback(d.ctx, "", cOptions, tOptions, cb)
 	if err != nil {
@@ -108,6 +125,27 @@ func (d *DeepgramProvider) Close() {
 // deepgramCallback implements the LiveMessageCallback interface.
 type deepgramCallback struct {
 	results chan TranscriptResult
+	lang    string
+	allowed []string
+}
+
+// language returns the language of a result: the configured one, or the
+// detected one when the language is auto.
+func (c *deepgramCallback) language(alt api.Alternative) string {
+	if !IsAutoLanguage(c.lang) {
+		return c.lang
+	}
+	reported := alt.Languages
+	if len(reported) == 0 {
+		langs := make([]string, len(alt.Words))
+		for i, w := range alt.Words {
+			langs[i] = w.Language
+		}
+		if l := DominantLanguage(langs); l != "" {
+			reported = []string{l}
+		}
+	}
+	return pickLanguage(reported, c.allowed)
 }
 
 func (c *deepgramCallback) Open(_ *api.OpenResponse) error {
@@ -131,6 +169,7 @@ func (c *deepgramCallback) Message(mr *api.MessageResponse) error {
 		Text:        text,
 		IsFinal:     mr.IsFinal,
 		SpeechFinal: mr.SpeechFinal,
+		Language:    c.language(mr.Channel.Alternatives[0]),
 	}
 
 	select {
@@ -25,6 +25,17 @@ func TestNewDeepgram(t *testing.T) {
 	}
 }
 
+func TestDeepgramSampleRate(t *testing.T) {
+	d := NewDeepgram("key", "en")
+	if d.rate != SampleRate {
+		t.Errorf("rate = %d, want %d", d.rate, SampleRate)
+	}
+	d.SetSampleRate(48000)
+	if d.rate != 48000 {
+		t.Errorf("rate = %d, want 48000", d.rate)
+	}
+}
+
 func TestDeepgramImplementsProvider(t *testing.T) {
 	var _ Provider = NewDeepgram("key", "en")
 }
@@ -233,3 +244,33 @@ func TestCallbackStubsReturnNil(t *testing.T) {
 		t.Errorf("Error: %v", err)
 	}
 }
+
+func TestCallbackLanguage(t *testing.T) {
+	ch := make(chan TranscriptResult, 8)
+	fixed := &deepgramCallback{results: ch, lang: "en-US"}
+	auto := &deepgramCallback{results: ch, lang: "auto", allowed: []string{"en", "es"}}
+
+	tests := []struct {
+		name string
+		cb   *deepgramCallback
+		alt  api.Alternative
+		want string
+	}{
+		{"fixed language", fixed, api.Alternative{Transcript: "hola", Languages: []string{"es"}}, "en-US"},
+		{"reported languages", auto, api.Alternative{Transcript: "hola amigo", Languages: []string{"es", "en"}}, "es"},
+		{"word languages", auto, api.Alternative{Transcript: "hola my friend", Words: []api.Word{
+			{Word: "hola", Language: "es"}, {Word: "my", Language: "en"}, {Word: "friend", Language: "en"},
+		}}, "en"},
+		{"outside the allowed list", auto, api.Alternative{Transcript: "ciao", Languages: []string{"it"}}, "it"},
+	}
+	for _, tt := range tests {
+		mr := &api.MessageResponse{IsFinal: true}
+		mr.Channel.Alternatives = []api.Alternative{tt.alt}
+		if err := tt.cb.Message(mr); err != nil {
+			t.Fatalf("%s: Message: %v", tt.name, err)
+		}
+		if got := <-ch; got.Language != tt.want {
+			t.Errorf("%s: Language = %q, want %q", tt.name, got.Language, tt.want)
+		}
+	}
+}
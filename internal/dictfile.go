@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Dictionary file formats understood by Import and Export.
+const (
+	FormatTOML = "toml"
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+var csvHeader = []string{"kind", "phrase", "value"}
+
+// FormatFromPath picks a dictionary file format from the file extension,
+// defaulting to TOML.
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".csv":
+		return FormatCSV
+	default:
+		return FormatTOML
+	}
+}
+
+// Export writes the dictionary to w in the given format. The output can be
+// read back with Import.
+func (d *Dictionary) Export(w io.Writer, format string) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	f := dictionaryFile{Words: d.entries, Commands: d.commands}
+
+	switch format {
+	case FormatTOML:
+		return toml.NewEncoder(w).Encode(f)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(f)
+	case FormatCSV:
+		return writeCSV(w, f)
+	default:
+		return fmt.Errorf("unknown format: %s (supported: toml, json, csv)", format)
+	}
+}
+
+func readDictionaryFile(path string) (dictionaryFile, error) {
+	var f dictionaryFile
+	switch FormatFromPath(path) {
+	case FormatJSON:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return f, err
+		}
+		if err := json.Unmarshal(data, &f); err != nil {
+			return f, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	case FormatCSV:
+		file, err := os.Open(path)
+		if err != nil {
+			return f, err
+		}
+		defer func() { _ = file.Close() }()
+		if f, err = readCSV(file); err != nil {
+			return f, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	default:
+		if _, err := toml.DecodeFile(path, &f); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+// writeCSV writes one row per entry, sorted by kind then phrase.
+func writeCSV(w io.Writer, f dictionaryFile) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, kind := range []string{"word", "command"} {
+		entries := f.Words
+		if kind == "command" {
+			entries = f.Commands
+		}
+		phrases := make([]string, 0, len(entries))
+		for phrase := range entries {
+			phrases = append(phrases, phrase)
+		}
+		sort.Strings(phrases)
+		for _, phrase := range phrases {
+			if err := cw.Write([]string{kind, phrase, entries[phrase]}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// readCSV reads rows written by writeCSV. A two-column "phrase,value" file
+// without a kind column is read as word replacements.
+func readCSV(r io.Reader) (dictionaryFile, error) {
+	f := dictionaryFile{Words: map[string]string{}, Commands: map[string]string{}}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return f, err
+	}
+	for i, row := range rows {
+		if i == 0 && strings.EqualFold(strings.Join(row, ","), strings.Join(csvHeader, ",")) {
+			continue
+		}
+		switch len(row) {
+		case 2:
+			f.Words[row[0]] = row[1]
+		case 3:
+			switch row[0] {
+			case "word":
+				f.Words[row[1]] = row[2]
+			case "command":
+				f.Commands[row[1]] = row[2]
+			default:
+				return f, fmt.Errorf("line %d: unknown kind %q", i+1, row[0])
+			}
+		default:
+			return f, fmt.Errorf("line %d: expected 2 or 3 fields, got %d", i+1, len(row))
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestDictionary() *Dictionary {
+	return &Dictionary{
+		entries: map[string]string{
+			"new line":      "\n",
+			"switch commit": `git commit -m "{rest}"`,
+			"comma":         ",",
+		},
+		commands: map[string]string{
+			"press enter": "enter",
+		},
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]string{
+		"dict.toml": FormatTOML,
+		"dict.JSON": FormatJSON,
+		"dict.csv":  FormatCSV,
+		"dict":      FormatTOML,
+		"":          FormatTOML,
+	}
+	for path, want := range tests {
+		if got := FormatFromPath(path); got != want {
+			t.Errorf("FormatFromPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatTOML, FormatJSON, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			src := newTestDictionary()
+
+			path := filepath.Join(t.TempDir(), "dict."+format)
+			f, err := os.Create(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := src.Export(f, format); err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+			_ = f.Close()
+
+			dst := &Dictionary{entries: map[string]string{}}
+			res, err := dst.Import(path, ImportReplace)
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			if res.Added != 4 {
+				t.Errorf("Added = %d, want 4", res.Added)
+			}
+			if !reflect.DeepEqual(dst.List(), src.List()) {
+				t.Errorf("words = %v, want %v", dst.List(), src.List())
+			}
+			if !reflect.DeepEqual(dst.CommandList(), src.CommandList()) {
+				t.Errorf("commands = %v, want %v", dst.CommandList(), src.CommandList())
+			}
+		})
+	}
+}
+
+func TestExportCSVIsSorted(t *testing.T) {
+	var b strings.Builder
+	if err := newTestDictionary().Export(&b, FormatCSV); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if lines[0] != "kind,phrase,value" {
+		t.Errorf("header = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "word,comma,") {
+		t.Errorf("first row = %q, want comma first", lines[1])
+	}
+	if !strings.HasPrefix(lines[len(lines)-1], "command,press enter,") {
+		t.Errorf("last row = %q, want command last", lines[len(lines)-1])
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	var b strings.Builder
+	if err := newTestDictionary().Export(&b, "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestReadCSVTwoColumns(t *testing.T) {
+	f, err := readCSV(strings.NewReader("period,.\nnew line,\"\n\"\n"))
+	if err != nil {
+		t.Fatalf("readCSV: %v", err)
+	}
+	if f.Words["period"] != "." || f.Words["new line"] != "\n" {
+		t.Errorf("words = %v", f.Words)
+	}
+}
+
+func TestReadCSVBadKind(t *testing.T) {
+	if _, err := readCSV(strings.NewReader("macro,x,y\n")); err == nil {
+		t.Error("expected error for unknown kind")
+	}
+}
+
+func writeImportFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "import.toml")
+	data := "[words]\n\"comma\" = \"COMMA\"\n\"period\" = \".\"\n\"new line\" = \"\\n\"\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImportReplaceOverwritesConflicts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	d := newTestDictionary()
+	res, err := d.Import(writeImportFile(t), ImportReplace)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if res.Added != 1 || res.Updated != 1 || res.Skipped != 0 {
+		t.Errorf("result = %+v, want 1 added, 1 updated", res)
+	}
+	if d.List()["comma"] != "COMMA" {
+		t.Errorf("comma = %q, want overwritten", d.List()["comma"])
+	}
+}
+
+func TestImportNormalizesLikeAdd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "import.toml")
+	data := "[words]\n\"Kube Control.\" = \"kubectl\"\n[commands]\n\"Press, Enter!\" = \"enter\"\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	d := newTestDictionary()
+	if _, err := d.Import(path, ImportReplace); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if got := d.List()["kube control"]; got != "kubectl" {
+		t.Errorf("words = %v, want \"kube control\" key", d.List())
+	}
+	if got := d.CommandList()["press enter"]; got != "enter" {
+		t.Errorf("commands = %v, want \"press enter\" key", d.CommandList())
+	}
+}
+
+func TestImportMergeKeepsExisting(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	d := newTestDictionary()
+	res, err := d.Import(writeImportFile(t), ImportMerge)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if res.Added != 1 || res.Updated != 0 || res.Skipped != 1 {
+		t.Errorf("result = %+v, want 1 added, 1 skipped", res)
+	}
+	if !reflect.DeepEqual(res.Conflict, []string{"comma"}) {
+		t.Errorf("Conflict = %v, want [comma]", res.Conflict)
+	}
+	if d.List()["comma"] != "," {
+		t.Errorf("comma = %q, want kept", d.List()["comma"])
+	}
+}
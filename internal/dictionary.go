@@ -1,25 +1,54 @@
 package internal
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"unicode"
 
 	"github.com/BurntSushi/toml"
 )
 
 type Dictionary struct {
-	mu      sync.RWMutex
-	entries map[string]string // lowercase spoken phrase → replacement
+	mu       sync.RWMutex
+	entries  map[string]string // lowercase spoken phrase → replacement
+	commands map[string]string // lowercase spoken phrase → action spec
+
+	// Read-only layers merged around the personal entries when matching:
+	// below (e.g. the team dictionary) and above (project, profile).
+	below []Layer
+	above []Layer
+
+	// templateVars supplies snippet placeholder values; nil uses the real
+	// clock, clipboard and git branch.
+	templateVars func() TemplateVars
+	// dir is where {branch} is read from; empty uses golos's own directory.
+	dir string
+}
+
+// rule is a dictionary entry compiled into normalized phrase tokens.
+type rule struct {
+	tokens      []string
+	replacement string
+}
+
+// token is a whitespace-separated word of a transcript with its byte span.
+type token struct {
+	start, end int
+	norm       string
 }
 
 type dictionaryFile struct {
-	Words map[string]string `toml:"words"`
+	Words    map[string]string `toml:"words" json:"words"`
+	Commands map[string]string `toml:"commands,omitempty" json:"commands,omitempty"`
 }
 
 func LoadDictionary() *Dictionary {
-	d := &Dictionary{entries: make(map[string]string)}
+	d := &Dictionary{entries: make(map[string]string), commands: make(map[string]string)}
+	d.ReloadTeam()
 
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -39,6 +68,9 @@ func LoadDictionary() *Dictionary {
 	for phrase, replacement := range f.Words {
 		d.entries[strings.ToLower(phrase)] = replacement
 	}
+	for phrase, spec := range f.Commands {
+		d.commands[normalizePhrase(phrase)] = spec
+	}
 
 	return d
 }
@@ -48,21 +80,47 @@ func dictionaryPath() string {
 	return filepath.Join(home, ".config", "golos", "dictionary.toml")
 }
 
+// normalizeWord lowercases a word and strips the punctuation that
+// smart formatting attaches to it ("Switch," → "switch").
+func normalizeWord(w string) string {
+	return strings.ToLower(strings.TrimFunc(w, unicode.IsPunct))
+}
+
+// normalizePhrase reduces a phrase to its normalized words joined by single
+// spaces, so "Switch, commit." and "switch commit" share one key.
+func normalizePhrase(phrase string) string {
+	var words []string
+	for _, w := range strings.Fields(phrase) {
+		if n := normalizeWord(w); n != "" {
+			words = append(words, n)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
 func (d *Dictionary) Add(phrase, replacement string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	d.entries[strings.ToLower(phrase)] = replacement
+	d.entries[normalizePhrase(phrase)] = replacement
 	return d.save()
 }
 
+// Delete removes the entry for phrase along with any punctuation or casing
+// variants of it.
 func (d *Dictionary) Delete(phrase string) bool {
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	key := strings.ToLower(phrase)
-	if _, ok := d.entries[key]; !ok {
+	norm := normalizePhrase(phrase)
+	found := false
+	for key := range d.entries {
+		if key == strings.ToLower(phrase) || normalizePhrase(key) == norm {
+			delete(d.entries, key)
+			found = true
+		}
+	}
+	if !found {
 		return false
 	}
-	delete(d.entries, key)
 	_ = d.save()
 	return true
 }
@@ -87,47 +145,349 @@ func (d *Dictionary) save() error {
 	}
 	defer func() { _ = f.Close() }()
 
-	return toml.NewEncoder(f).Encode(dictionaryFile{Words: d.entries})
+	return toml.NewEncoder(f).Encode(dictionaryFile{Words: d.entries, Commands: d.commands})
 }
 
-// Import merges entries from a TOML file into the dictionary and saves.
-func (d *Dictionary) Import(path string) (int, error) {
-	var f dictionaryFile
-	if _, err := toml.DecodeFile(path, &f); err != nil {
-		return 0, err
+// ImportMode controls what Import does when an imported phrase already
+// exists in the dictionary.
+type ImportMode int
+
+const (
+	ImportReplace ImportMode = iota // imported entries overwrite existing ones
+	ImportMerge                     // existing entries are kept
+)
+
+// ImportResult counts what an Import did.
+type ImportResult struct {
+	Added    int
+	Updated  int
+	Skipped  int // conflicts kept as they were (ImportMerge)
+	Conflict []string
+}
+
+// Import merges entries from a TOML, JSON or CSV file into the dictionary
+// and saves. The format is chosen by the file extension.
+func (d *Dictionary) Import(path string, mode ImportMode) (ImportResult, error) {
+	f, err := readDictionaryFile(path)
+	if err != nil {
+		return ImportResult{}, err
 	}
 
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	count := 0
-	for phrase, replacement := range f.Words {
-		d.entries[strings.ToLower(phrase)] = replacement
-		count++
+	if d.commands == nil {
+		d.commands = make(map[string]string)
+	}
+
+	var res ImportResult
+	merge := func(dst map[string]string, key, value string) {
+		old, exists := dst[key]
+		switch {
+		case !exists:
+			dst[key] = value
+			res.Added++
+		case old == value:
+		case mode == ImportMerge:
+			res.Skipped++
+			res.Conflict = append(res.Conflict, key)
+		default:
+			dst[key] = value
+			res.Updated++
+			res.Conflict = append(res.Conflict, key)
+		}
+	}
+	// Phrases are normalized as Add does. Sorting makes the outcome
+	// predictable when two of them normalize alike.
+	for _, src := range []struct{ from, to map[string]string }{{f.Words, d.entries}, {f.Commands, d.commands}} {
+		phrases := make([]string, 0, len(src.from))
+		for phrase := range src.from {
+			phrases = append(phrases, phrase)
+		}
+		sort.Strings(phrases)
+		for _, phrase := range phrases {
+			merge(src.to, normalizePhrase(phrase), src.from[phrase])
+		}
 	}
+	sort.Strings(res.Conflict)
 
-	return count, d.save()
+	return res, d.save()
+}
+
+// Variants groups entries whose phrases differ only in punctuation or
+// casing, keyed by the normalized phrase. Only groups with more than one
+// entry are returned.
+func (d *Dictionary) Variants() map[string][]string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	groups := make(map[string][]string)
+	for key := range d.entries {
+		norm := normalizePhrase(key)
+		groups[norm] = append(groups[norm], key)
+	}
+	for norm, keys := range groups {
+		if len(keys) < 2 {
+			delete(groups, norm)
+			continue
+		}
+		sort.Strings(keys)
+	}
+	return groups
 }
 
+// Collapse replaces each group of variant entries with a single entry keyed
+// by the normalized phrase and saves. Groups whose replacements disagree are
+// left untouched. It returns the number of entries removed.
+func (d *Dictionary) Collapse() (int, error) {
+	groups := d.Variants()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	removed := 0
+	for norm, keys := range groups {
+		replacement := d.entries[keys[0]]
+		agree := true
+		for _, k := range keys[1:] {
+			if d.entries[k] != replacement {
+				agree = false
+				break
+			}
+		}
+		if !agree {
+			continue
+		}
+		for _, k := range keys {
+			delete(d.entries, k)
+		}
+		d.entries[norm] = replacement
+		removed += len(keys) - 1
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, d.save()
+}
+
+// CommandList returns a copy of the voice commands, phrase → action spec.
+func (d *Dictionary) CommandList() map[string]string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	out := make(map[string]string, len(d.commands))
+	for k, v := range d.commands {
+		out[k] = v
+	}
+	return out
+}
+
+// compileRules turns phrase → value entries into normalized token sequences,
+// longest phrase first so "skip permissions" wins over "skip".
+func compileRules(entries map[string]string) []rule {
+	rules := make([]rule, 0, len(entries))
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		norm := normalizePhrase(key)
+		if norm == "" || seen[norm] {
+			continue
+		}
+		seen[norm] = true
+		rules = append(rules, rule{tokens: strings.Fields(norm), replacement: entries[key]})
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return len(rules[i].tokens) > len(rules[j].tokens)
+	})
+	return rules
+}
+
+func tokenize(text string) []token {
+	var toks []token
+	start := -1
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				toks = append(toks, token{start: start, end: i, norm: normalizeWord(text[start:i])})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		toks = append(toks, token{start: start, end: len(text), norm: normalizeWord(text[start:])})
+	}
+	return toks
+}
+
+// matchAt reports whether the phrase tokens match the transcript starting at
+// toks[i], skipping punctuation-only words between them. It returns the index
+// just past the last matched word.
+func matchAt(toks []token, i int, phrase []string) (int, bool) {
+	j := i
+	for k, want := range phrase {
+		if k > 0 {
+			for j < len(toks) && toks[j].norm == "" {
+				j++
+			}
+		}
+		if j >= len(toks) || toks[j].norm != want {
+			return 0, false
+		}
+		j++
+	}
+	return j, true
+}
+
+// Replace substitutes dictionary phrases in text. Matching is word-based and
+// ignores casing and punctuation around the phrase words, so a single
+// "switch commit" entry also matches "Switch, commit." — the punctuation
+// inside the matched span is replaced along with the words.
 func (d *Dictionary) Replace(text string) string {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	if len(d.entries) == 0 {
+	words, _ := d.merged()
+	if len(words) == 0 {
 		return text
 	}
 
 	text = strings.TrimRight(text, ".!?")
-	for phrase, replacement := range d.entries {
-		offset := 0
-		for offset < len(text) {
-			idx := strings.Index(strings.ToLower(text[offset:]), phrase)
-			if idx == -1 {
-				break
+	vars := d.vars()
+	return substitute(text, compileRules(words), func(r rule, rest string) (string, bool) {
+		if !hasRest(r.replacement) {
+			return ExpandTemplate(r.replacement, vars), false
+		}
+		vars.Rest = strings.TrimLeft(rest, " ,;:")
+		return ExpandTemplate(r.replacement, vars), true
+	})
+}
+
+func (d *Dictionary) vars() TemplateVars {
+	if d.templateVars != nil {
+		return d.templateVars()
+	}
+	return defaultTemplateVars(d.dir)
+}
+
+// ExtractCommands removes spoken command phrases from the end of text and
+// returns the remaining text along with the parsed actions in the order they
+// were spoken. Only a run of commands that ends the utterance counts, so a
+// command phrase in the middle of a sentence is left as dictated text.
+// Commands with an invalid action spec are left in the text.
+func (d *Dictionary) ExtractCommands(text string) (string, []Action) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	_, commands := d.merged()
+	if len(commands) == 0 {
+		return text, nil
+	}
+
+	rules := compileRules(commands)
+	kept := rules[:0]
+	for _, r := range rules {
+		if _, err := ParseAction(r.replacement); err != nil {
+			fmt.Fprintf(os.Stderr, "dictionary: command %q: %v\n", strings.Join(r.tokens, " "), err)
+			continue
+		}
+		kept = append(kept, r)
+	}
+
+	var actions []Action
+	toks := tokenize(text)
+	end, cut := len(toks), len(text)
+	for {
+		for end > 0 && toks[end-1].norm == "" {
+			end--
+		}
+		matched := false
+		for _, r := range kept {
+			start, ok := matchEnding(toks, end, r.tokens)
+			if !ok {
+				continue
+			}
+			a, _ := ParseAction(r.replacement)
+			actions = append([]Action{a}, actions...)
+			end, cut = start, toks[start].start
+			matched = true
+			break
+		}
+		if !matched {
+			break
+		}
+	}
+	if actions == nil {
+		return text, nil
+	}
+	return strings.TrimRightFunc(text[:cut], func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(",;:", r)
+	}), actions
+}
+
+// matchEnding reports whether the phrase tokens match the transcript words
+// ending just before toks[end], skipping punctuation-only words between
+// them. It returns the index of the first matched word.
+func matchEnding(toks []token, end int, phrase []string) (int, bool) {
+	j := end - 1
+	for k := len(phrase) - 1; k >= 0; k-- {
+		if k < len(phrase)-1 {
+			for j >= 0 && toks[j].norm == "" {
+				j--
 			}
-			pos := offset + idx
-			text = text[:pos] + replacement + text[pos+len(phrase):]
-			offset = pos + len(replacement)
+		}
+		if j < 0 || toks[j].norm != phrase[k] {
+			return 0, false
+		}
+		j--
+	}
+	return j + 1, true
+}
+
+// substitute replaces every match of rules in text with the result of emit,
+// scanning left to right so replaced text is never matched again. emit is
+// given the text following the match and reports whether it consumed it,
+// which ends the scan. Punctuation before the first matched word and after
+// the last one is kept around the replacement, so "Cube control, now"
+// still has its comma.
+func substitute(text string, rules []rule, emit func(r rule, rest string) (string, bool)) string {
+	toks := tokenize(text)
+
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(toks); {
+		if toks[i].norm == "" {
+			i++
+			continue
+		}
+		matched := false
+		for _, r := range rules {
+			end, ok := matchAt(toks, i, r.tokens)
+			if !ok {
+				continue
+			}
+			first := text[toks[i].start:toks[i].end]
+			final := text[toks[end-1].start:toks[end-1].end]
+			b.WriteString(text[last:toks[i].start])
+			b.WriteString(first[:len(first)-len(strings.TrimLeftFunc(first, unicode.IsPunct))])
+			out, consumed := emit(r, text[toks[end-1].end:])
+			b.WriteString(out)
+			last = toks[end-1].end
+			i = end
+			if consumed {
+				last, i = len(text), len(toks)
+			} else {
+				b.WriteString(final[len(strings.TrimRightFunc(final, unicode.IsPunct)):])
+			}
+			matched = true
+			break
+		}
+		if !matched {
+			i++
 		}
 	}
+	b.WriteString(text[last:])
 
-	return text
+	return b.String()
 }
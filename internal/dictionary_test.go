@@ -56,3 +56,264 @@ func TestLoadDictionaryReturnsNonNil(t *testing.T) {
 		t.Fatal("expected non-nil dictionary")
 	}
 }
+
+func TestReplaceIgnoresProviderPunctuation(t *testing.T) {
+	d := &Dictionary{entries: map[string]string{
+		"switch commit": "git commit",
+	}}
+	got := d.Replace("Switch, commit.")
+	if got != "git commit" {
+		t.Errorf("got %q, want %q", got, "git commit")
+	}
+}
+
+func TestReplacePunctuationMidSentence(t *testing.T) {
+	d := &Dictionary{entries: map[string]string{
+		"skip": "claude --dangerously-skip-permissions",
+	}}
+	got := d.Replace("okay, Skip, then go")
+	if got != "okay, claude --dangerously-skip-permissions, then go" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestReplaceKeepsSurroundingPunctuation(t *testing.T) {
+	d := &Dictionary{entries: map[string]string{
+		"cube control": "kubectl",
+		"skip":         "Skip it",
+	}}
+	tests := map[string]string{
+		"Cube control, now":   "kubectl, now",
+		"okay, Skip, then go": "okay, Skip it, then go",
+		"run (cube control)":  "run (kubectl)",
+	}
+	for in, want := range tests {
+		if got := d.Replace(in); got != want {
+			t.Errorf("Replace(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestReplaceVariantKeysMatchNormalized(t *testing.T) {
+	d := &Dictionary{entries: map[string]string{
+		"skip.": "claude",
+	}}
+	got := d.Replace("run skip now")
+	if got != "run claude now" {
+		t.Errorf("got %q, want %q", got, "run claude now")
+	}
+}
+
+func TestReplaceLongestPhraseWins(t *testing.T) {
+	d := &Dictionary{entries: map[string]string{
+		"skip":             "A",
+		"skip permissions": "B",
+	}}
+	got := d.Replace("skip permissions, skip")
+	if got != "B, A" {
+		t.Errorf("got %q, want %q", got, "B, A")
+	}
+}
+
+func TestReplaceWholeWordsOnly(t *testing.T) {
+	d := &Dictionary{entries: map[string]string{
+		"dash": "-",
+	}}
+	got := d.Replace("open the dashboard")
+	if got != "open the dashboard" {
+		t.Errorf("got %q, want %q", got, "open the dashboard")
+	}
+}
+
+func TestNormalizePhrase(t *testing.T) {
+	tests := map[string]string{
+		"Switch, commit.": "switch commit",
+		"skip,":           "skip",
+		"  new   line ":   "new line",
+		"...":             "",
+	}
+	for in, want := range tests {
+		if got := normalizePhrase(in); got != want {
+			t.Errorf("normalizePhrase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestVariants(t *testing.T) {
+	d := &Dictionary{entries: map[string]string{
+		"skip":   "A",
+		"skip.":  "A",
+		"skip,":  "A",
+		"period": ".",
+	}}
+	groups := d.Variants()
+	if len(groups) != 1 {
+		t.Fatalf("got %d groups, want 1", len(groups))
+	}
+	if len(groups["skip"]) != 3 {
+		t.Errorf("skip group = %v, want 3 entries", groups["skip"])
+	}
+}
+
+func TestCollapse(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	d := &Dictionary{entries: map[string]string{
+		"skip":            "A",
+		"skip.":           "A",
+		"switch, commit.": "B",
+		"switch commit":   "B",
+		"go.":             "C",
+		"go":              "D",
+	}}
+	removed, err := d.Collapse()
+	if err != nil {
+		t.Fatalf("Collapse: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("removed = %d, want 2", removed)
+	}
+	entries := d.List()
+	if entries["skip"] != "A" || entries["switch commit"] != "B" {
+		t.Errorf("unexpected entries: %v", entries)
+	}
+	if _, ok := entries["go."]; !ok {
+		t.Error("conflicting variants should be left untouched")
+	}
+}
+
+func TestDeleteRemovesVariants(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	d := &Dictionary{entries: map[string]string{
+		"skip":  "A",
+		"skip.": "A",
+	}}
+	if !d.Delete("Skip") {
+		t.Fatal("Delete returned false")
+	}
+	if len(d.List()) != 0 {
+		t.Errorf("entries = %v, want empty", d.List())
+	}
+}
+
+func TestExtractCommands(t *testing.T) {
+	d := &Dictionary{commands: map[string]string{
+		"press enter": "enter",
+		"interrupt":   "key escape",
+	}}
+	text, actions := d.ExtractCommands("Fix the login bug. Press enter.")
+	if text != "Fix the login bug." {
+		t.Errorf("text = %q, want %q", text, "Fix the login bug.")
+	}
+	if len(actions) != 1 || actions[0].Kind != ActionEnter {
+		t.Errorf("actions = %+v, want [enter]", actions)
+	}
+}
+
+func TestExtractCommandsOrderAndCommandOnly(t *testing.T) {
+	d := &Dictionary{commands: map[string]string{
+		"interrupt":    "key escape",
+		"scratch that": "undo",
+	}}
+	text, actions := d.ExtractCommands("Scratch that, interrupt.")
+	if text != "" {
+		t.Errorf("text = %q, want empty", text)
+	}
+	if len(actions) != 2 || actions[0].Kind != ActionUndo || actions[1].Kind != ActionKey {
+		t.Errorf("actions = %+v, want [undo key]", actions)
+	}
+}
+
+func TestExtractCommandsOnlyAtEnd(t *testing.T) {
+	d := &Dictionary{commands: map[string]string{
+		"press enter": "enter",
+		"new line":    "key shift+enter",
+	}}
+	in := "Type new line to break it,\nthen press enter to send"
+	if text, actions := d.ExtractCommands(in); text != in || actions != nil {
+		t.Errorf("got %q %+v, want text untouched and no actions", text, actions)
+	}
+	text, actions := d.ExtractCommands("First line\n\nsecond line, new line. Press enter")
+	if text != "First line\n\nsecond line" {
+		t.Errorf("text = %q, want %q", text, "First line\n\nsecond line")
+	}
+	if len(actions) != 2 || actions[0].Kind != ActionKey || actions[1].Kind != ActionEnter {
+		t.Errorf("actions = %+v, want [key enter]", actions)
+	}
+}
+
+func TestExtractCommandsSkipsInvalidSpec(t *testing.T) {
+	d := &Dictionary{commands: map[string]string{
+		"launch": "rockets",
+	}}
+	text, actions := d.ExtractCommands("launch now")
+	if text != "launch now" || len(actions) != 0 {
+		t.Errorf("got %q %+v, want text untouched and no actions", text, actions)
+	}
+}
+
+func TestExtractCommandsEmpty(t *testing.T) {
+	d := &Dictionary{entries: map[string]string{}}
+	text, actions := d.ExtractCommands("hello")
+	if text != "hello" || actions != nil {
+		t.Errorf("got %q %+v", text, actions)
+	}
+}
+
+func TestReplaceSnippetRest(t *testing.T) {
+	d := &Dictionary{
+		entries: map[string]string{
+			"switch commit": `git add -A && git commit -m "{rest}" && git push origin {branch}`,
+		},
+		templateVars: testVars,
+	}
+	got := d.Replace("Switch, commit, fix login bug.")
+	want := `git add -A && git commit -m "fix login bug" && git push origin main`
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestReplaceSnippetRestEmpty(t *testing.T) {
+	d := &Dictionary{
+		entries:      map[string]string{"note": "# {rest}"},
+		templateVars: testVars,
+	}
+	if got := d.Replace("note"); got != "# " {
+		t.Errorf("got %q, want %q", got, "# ")
+	}
+}
+
+func TestReplaceSnippetWithoutRestKeepsScanning(t *testing.T) {
+	d := &Dictionary{
+		entries: map[string]string{
+			"today":  "{date}",
+			"period": ".",
+		},
+		templateVars: testVars,
+	}
+	if got := d.Replace("due today period"); got != "due 2026-03-07 ." {
+		t.Errorf("got %q, want %q", got, "due 2026-03-07 .")
+	}
+}
+
+func TestWithLayer(t *testing.T) {
+	d := &Dictionary{
+		entries:  map[string]string{"new line": "\n", "new line.": "\n", "comma": ","},
+		commands: map[string]string{"press enter": "enter"},
+	}
+	o := d.WithLayer(Layer{
+		Source:   "profile test",
+		Words:    map[string]string{"New line": "⏎"},
+		Commands: map[string]string{"interrupt": "key escape"},
+	})
+
+	if got := o.Replace("a new line b, comma"); got != "a ⏎ b, ," {
+		t.Errorf("Replace = %q, want %q", got, "a ⏎ b, ,")
+	}
+	if _, actions := o.ExtractCommands("interrupt press enter"); len(actions) != 2 {
+		t.Errorf("actions = %v, want 2", actions)
+	}
+	if d.List()["new line"] != "\n" || len(d.CommandList()) != 1 {
+		t.Error("overlay must not modify the base dictionary")
+	}
+}
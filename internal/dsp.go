@@ -0,0 +1,224 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+)
+
+// DSPConfig turns on conditioning of the mic audio before it reaches the
+// VAD and the provider. Everything is off by default.
+type DSPConfig struct {
+	HighPass int  `toml:"high_pass"` // cutoff in Hz of a filter removing DC offset and rumble; 0 is off
+	Noise    bool `toml:"noise"`     // attenuate the steady background between words
+	AGC      bool `toml:"agc"`       // bring quiet and loud speakers to a common level
+
+	NoiseReduction float64 `toml:"noise_reduction"` // dB taken off the background, default 12
+	Target         float64 `toml:"target"`          // level the AGC aims for in dBFS, default -20
+	MaxGain        float64 `toml:"max_gain"`        // most the AGC will boost, in dB, default 30
+}
+
+// DSP runs the enabled stages over each frame: high-pass, then noise
+// suppression, then gain control, so the gain is set on the cleaned-up
+// signal.
+type DSP struct {
+	highPass *biquad
+	noise    *noiseGate
+	agc      *agc
+}
+
+// NewDSP returns the chain the config asks for at the given sample rate,
+// or nil if it asks for none.
+func NewDSP(cfg DSPConfig, rate int) (*DSP, error) {
+	if cfg.HighPass == 0 && !cfg.Noise && !cfg.AGC {
+		return nil, nil
+	}
+	d := &DSP{}
+	if cfg.HighPass != 0 {
+		if cfg.HighPass < 0 || cfg.HighPass >= rate/2 {
+			return nil, fmt.Errorf("dsp: high_pass %d Hz must be between 0 and %d", cfg.HighPass, rate/2)
+		}
+		d.highPass = newHighPass(float64(cfg.HighPass), float64(rate))
+	}
+	if cfg.Noise {
+		reduction := cfg.NoiseReduction
+		if reduction == 0 {
+			reduction = 12
+		}
+		if reduction < 0 {
+			return nil, fmt.Errorf("dsp: noise_reduction %g dB must be positive", reduction)
+		}
+		d.noise = &noiseGate{floorGain: fromDB(-reduction)}
+	}
+	if cfg.AGC {
+		target, maxGain := cfg.Target, cfg.MaxGain
+		if target == 0 {
+			target = -20
+		}
+		if maxGain == 0 {
+			maxGain = 30
+		}
+		if target >= 0 || maxGain < 0 {
+			return nil, fmt.Errorf("dsp: agc target %g dBFS must be below 0 and max_gain %g dB positive", target, maxGain)
+		}
+		d.agc = &agc{target: fromDB(target) * math.MaxInt16, maxGain: fromDB(maxGain), gain: 1}
+	}
+	return d, nil
+}
+
+// Process conditions a frame in place and returns it.
+func (d *DSP) Process(frame []int16) []int16 {
+	buf := make([]float64, len(frame))
+	for i, s := range frame {
+		buf[i] = float64(s)
+	}
+	if d.highPass != nil {
+		d.highPass.process(buf)
+	}
+	if d.noise != nil {
+		d.noise.process(buf)
+	}
+	if d.agc != nil {
+		d.agc.process(buf)
+	}
+	for i, v := range buf {
+		frame[i] = int16(max(math.MinInt16, min(math.MaxInt16, math.Round(v))))
+	}
+	return frame
+}
+
+// Reset clears the state carried between frames, for a new session.
+func (d *DSP) Reset() {
+	if d.highPass != nil {
+		d.highPass.x1, d.highPass.x2, d.highPass.y1, d.highPass.y2 = 0, 0, 0, 0
+	}
+	if d.noise != nil {
+		d.noise.floor, d.noise.gain = 0, 0
+	}
+	if d.agc != nil {
+		d.agc.gain = 1
+	}
+}
+
+func fromDB(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+func frameRMS(buf []float64) float64 {
+	var sum float64
+	for _, v := range buf {
+		sum += v * v
+	}
+	return math.Sqrt(sum / float64(len(buf)))
+}
+
+// ramp scales buf by a gain moving linearly from from to to, so gain
+// changes between frames don't click.
+func ramp(buf []float64, from, to float64) {
+	for i := range buf {
+		buf[i] *= from + (to-from)*float64(i+1)/float64(len(buf))
+	}
+}
+
+// biquad is a second-order IIR filter.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+// newHighPass returns a Butterworth high-pass filter.
+func newHighPass(cutoff, rate float64) *biquad {
+	w := 2 * math.Pi * cutoff / rate
+	alpha := math.Sin(w) / math.Sqrt2 // Q = 1/√2
+	cos := math.Cos(w)
+	a0 := 1 + alpha
+	return &biquad{
+		b0: (1 + cos) / 2 / a0,
+		b1: -(1 + cos) / a0,
+		b2: (1 + cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+func (f *biquad) process(buf []float64) {
+	for i, x := range buf {
+		y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+		f.x2, f.x1 = f.x1, x
+		f.y2, f.y1 = f.y1, y
+		buf[i] = y
+	}
+}
+
+// noiseGate tracks the level of the background noise and turns down
+// frames that don't rise clearly above it. It is much cheaper than
+// spectral methods and leaves speech untouched.
+type noiseGate struct {
+	floorGain float64 // gain applied to frames at the noise floor
+	floor     float64 // estimated background level; 0 until the first frame
+	gain      float64
+}
+
+const (
+	noiseFloorRise = 1.003 // per frame while louder than the floor: ~1.3 dB/s
+	noiseOpenSNR   = 4     // frames this far above the floor (12 dB) pass untouched
+)
+
+func (g *noiseGate) process(buf []float64) {
+	level := frameRMS(buf)
+	if g.floor == 0 {
+		g.floor, g.gain = max(level, 1), 1
+	}
+	// The floor follows the level down quickly and creeps up slowly, so
+	// it settles on the quiet stretches between words.
+	if level < g.floor {
+		g.floor = max(g.floor+(level-g.floor)*0.5, 1)
+	} else {
+		g.floor *= noiseFloorRise
+	}
+
+	snr := level / g.floor
+	target := 1.0
+	if snr < noiseOpenSNR {
+		open := max(0, (snr-1)/(noiseOpenSNR-1))
+		target = g.floorGain + (1-g.floorGain)*open
+	}
+	// Open at once so word onsets aren't cut; close gradually.
+	if target < g.gain {
+		target = g.gain + (target-g.gain)*0.2
+	}
+	ramp(buf, g.gain, target)
+	g.gain = target
+}
+
+// agc moves the gain toward what brings speech to the target level,
+// quickly when the signal is too loud and slowly when it is too quiet.
+// Frames too quiet to be speech leave the gain alone.
+type agc struct {
+	target  float64 // RMS level aimed for
+	maxGain float64
+	gain    float64
+}
+
+// agcGate is the level below which a frame isn't worth adjusting for:
+// about -60 dBFS.
+const agcGate = 33
+
+func (a *agc) process(buf []float64) {
+	next := a.gain
+	if level := frameRMS(buf); level > agcGate {
+		want := min(a.target/level, a.maxGain)
+		if want < a.gain {
+			next = a.gain + (want-a.gain)*0.5
+		} else {
+			next = a.gain + (want-a.gain)*0.05
+		}
+	}
+	ramp(buf, a.gain, next)
+	a.gain = next
+	// Peaks that would still clip are limited rather than wrapped.
+	for i, v := range buf {
+		if math.Abs(v) > math.MaxInt16 {
+			buf[i] = math.Copysign(math.MaxInt16, v)
+		}
+	}
+}
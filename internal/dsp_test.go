@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// tone returns frames of a continuous sine wave.
+func tone(freq, amp float64, frames int) [][]int16 {
+	out := make([][]int16, frames)
+	for f := range out {
+		out[f] = make([]int16, testFrameSize)
+		for i := range out[f] {
+			n := f*testFrameSize + i
+			out[f][i] = int16(amp * math.Sin(2*math.Pi*freq*float64(n)/testSampleRate))
+		}
+	}
+	return out
+}
+
+// noise returns frames of white noise.
+func noise(amp float64, frames int) [][]int16 {
+	r := rand.New(rand.NewSource(1))
+	out := make([][]int16, frames)
+	for f := range out {
+		out[f] = make([]int16, testFrameSize)
+		for i := range out[f] {
+			out[f][i] = int16(amp * (2*r.Float64() - 1))
+		}
+	}
+	return out
+}
+
+func mix(a, b [][]int16) [][]int16 {
+	out := make([][]int16, len(a))
+	for f := range a {
+		out[f] = make([]int16, testFrameSize)
+		for i := range a[f] {
+			out[f][i] = a[f][i] + b[f][i]
+		}
+	}
+	return out
+}
+
+func frameLevel(frame []int16) float64 {
+	var sum float64
+	for _, s := range frame {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(frame)))
+}
+
+// run processes the frames and returns the level of the last ones.
+func run(t *testing.T, cfg DSPConfig, frames [][]int16) float64 {
+	t.Helper()
+	d, err := NewDSP(cfg, testSampleRate)
+	if err != nil {
+		t.Fatalf("NewDSP: %v", err)
+	}
+	var level float64
+	for i, frame := range frames {
+		d.Process(frame)
+		if i >= len(frames)-10 {
+			level += frameLevel(frame) / 10
+		}
+	}
+	return level
+}
+
+func TestNewDSPOff(t *testing.T) {
+	d, err := NewDSP(DSPConfig{}, testSampleRate)
+	if d != nil || err != nil {
+		t.Errorf("NewDSP(off) = %v, %v, want nil, nil", d, err)
+	}
+}
+
+func TestNewDSPErrors(t *testing.T) {
+	for _, cfg := range []DSPConfig{{HighPass: -1}, {HighPass: 8000}, {AGC: true, Target: 3}, {Noise: true, NoiseReduction: -6}} {
+		if _, err := NewDSP(cfg, testSampleRate); err == nil {
+			t.Errorf("NewDSP(%+v): expected error", cfg)
+		}
+	}
+}
+
+func TestHighPassRemovesDC(t *testing.T) {
+	frames := tone(400, 5000, 50)
+	for _, f := range frames {
+		for i := range f {
+			f[i] += 4000
+		}
+	}
+	d, _ := NewDSP(DSPConfig{HighPass: 80}, testSampleRate)
+	var sum float64
+	n := 0
+	for i, f := range frames {
+		d.Process(f)
+		if i >= 25 {
+			for _, s := range f {
+				sum += float64(s)
+				n++
+			}
+		}
+	}
+	if mean := sum / float64(n); math.Abs(mean) > 50 {
+		t.Errorf("mean = %.0f after high-pass, want about 0", mean)
+	}
+	if level := frameLevel(frames[len(frames)-1]); math.Abs(level-5000/math.Sqrt2) > 200 {
+		t.Errorf("400 Hz level = %.0f, want about %.0f", level, 5000/math.Sqrt2)
+	}
+}
+
+func TestHighPassCutsHum(t *testing.T) {
+	if level := run(t, DSPConfig{HighPass: 120}, tone(30, 10000, 50)); level > 10000/math.Sqrt2/10 {
+		t.Errorf("30 Hz level = %.0f after a 120 Hz high-pass, want at least 20 dB down", level)
+	}
+}
+
+func TestNoiseGateAttenuatesBackground(t *testing.T) {
+	in := 300 / math.Sqrt(3) // RMS of uniform noise of amplitude 300
+	if level := run(t, DSPConfig{Noise: true}, noise(300, 150)); level > in*fromDB(-8) {
+		t.Errorf("background level = %.0f, want at least 8 dB below %.0f", level, in)
+	}
+}
+
+func TestNoiseGatePassesSpeech(t *testing.T) {
+	frames := append(noise(300, 100), mix(tone(400, 8000, 10), noise(300, 10))...)
+	level := run(t, DSPConfig{Noise: true}, frames)
+	if want := 8000 / math.Sqrt2; math.Abs(level-want) > want*0.05 {
+		t.Errorf("speech level = %.0f after the noise gate, want about %.0f", level, want)
+	}
+}
+
+func TestAGCRaisesQuietSpeech(t *testing.T) {
+	level := run(t, DSPConfig{AGC: true}, tone(400, 500, 150))
+	if want := fromDB(-20) * math.MaxInt16; math.Abs(level-want) > want*0.1 {
+		t.Errorf("quiet speech level = %.0f after AGC, want about %.0f", level, want)
+	}
+}
+
+func TestAGCLowersLoudSpeechWithoutClipping(t *testing.T) {
+	frames := tone(400, 32000, 50)
+	level := run(t, DSPConfig{AGC: true}, frames)
+	if want := fromDB(-20) * math.MaxInt16; math.Abs(level-want) > want*0.1 {
+		t.Errorf("loud speech level = %.0f after AGC, want about %.0f", level, want)
+	}
+}
+
+func TestAGCLeavesSilenceAlone(t *testing.T) {
+	if level := run(t, DSPConfig{AGC: true}, noise(20, 100)); level > 20 {
+		t.Errorf("silence level = %.0f after AGC, want it not boosted", level)
+	}
+}
+
+func TestAGCMaxGain(t *testing.T) {
+	if level := run(t, DSPConfig{AGC: true, MaxGain: 6}, tone(400, 200, 150)); level > 200/math.Sqrt2*fromDB(6)*1.01 {
+		t.Errorf("level = %.0f, want at most 6 dB of gain", level)
+	}
+}
@@ -0,0 +1,217 @@
+package internal
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FillerConfig controls filler word and disfluency removal.
+type FillerConfig struct {
+	Enabled bool     `toml:"enabled"` // run before the dictionary in the default pipeline
+	Words   []string `toml:"words"`   // extra fillers on top of the language's defaults
+	Repeats bool     `toml:"repeats"` // drop repeated words and phrases ("the the")
+}
+
+// fillerWords are hesitation sounds, removed wherever they occur; words
+// that mean something in the language ("este", "ben", "é") don't belong
+// here. softFillers are real words, so they are only removed when set off
+// by commas ("it's, like, fine"). Both are keyed by base language.
+var (
+	fillerWords = map[string][]string{
+		"en": {"um", "umm", "uh", "uhh", "uhm", "er", "erm", "ah", "hmm", "mm"},
+		"es": {"eh", "em", "mmm"},
+		"fr": {"euh", "heu", "hum"},
+		"de": {"äh", "ähm", "öh", "hm", "hmm"},
+		"pt": {"hum", "hã", "ahn"},
+		"it": {"ehm", "eh", "mmm", "ah"},
+		"nl": {"eh", "uh", "uhm", "ehm"},
+	}
+	softFillers = map[string][]string{
+		"en": {"like", "you know", "I mean"},
+		"es": {"pues", "o sea", "bueno"},
+		"fr": {"genre", "tu vois", "en fait"},
+		"de": {"also", "halt", "sozusagen"},
+		"pt": {"tipo", "né", "sabe"},
+		"it": {"tipo", "cioè", "insomma"},
+		"nl": {"zeg maar", "dus"},
+	}
+	// repeatsAllowed are words that are legitimately doubled.
+	repeatsAllowed = map[string][]string{
+		"en": {"that", "had"},
+		"de": {"die", "das"},
+		"fr": {"nous", "vous"},
+	}
+)
+
+// baseLanguage reduces a language tag to its base: "en-US" → "en". An
+// empty tag is English, the default.
+func baseLanguage(lang string) string {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if lang == "" {
+		return "en"
+	}
+	return lang
+}
+
+// newFillerStage removes filler words, and repeated words if enabled under
+// [filler], using the defaults for the session's language plus any
+// configured words.
+func newFillerStage(stage StageConfig, cfg *Config) (Transformer, error) {
+	extra := append(append([]string{}, cfg.Filler.Words...), stage.Words...)
+	repeats := cfg.Filler.Repeats
+	return TransformerFunc(func(text string, s Session) (string, error) {
+		return RemoveFillers(text, baseLanguage(s.Language), extra, repeats), nil
+	}), nil
+}
+
+// RemoveFillers drops filler words for lang (a base language such as
+// "en") and the extra words, and, if repeats is set, the first copy of
+// words or phrases said twice in a row.
+func RemoveFillers(text, lang string, extra []string, repeats bool) string {
+	words, tail := splitWords(text)
+	hard := compilePhrases(append(append([]string{}, fillerWords[lang]...), extra...))
+	soft := compilePhrases(softFillers[lang])
+	allowed := map[string]bool{}
+	for _, w := range repeatsAllowed[lang] {
+		allowed[w] = true
+	}
+
+	out := make([]word, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		n := phraseAt(words, i, hard)
+		if n == 0 && setOff(out, words, i, phraseAt(words, i, soft)) {
+			n = phraseAt(words, i, soft)
+		}
+		if n == 0 && repeats {
+			n = repeatAt(words, i, allowed)
+		}
+		if n == 0 {
+			out = append(out, words[i])
+			continue
+		}
+		out = dropWords(out, words, i, n)
+		i += n - 1
+	}
+	return joinWords(out, tail)
+}
+
+// setOff reports whether the n words at words[i] stand apart from the
+// sentence: at its start or after a comma, and followed by a comma.
+func setOff(out, words []word, i, n int) bool {
+	if n == 0 {
+		return false
+	}
+	_, _, trail := words[i+n-1].split()
+	if !strings.Contains(trail, ",") {
+		return false
+	}
+	return len(out) == 0 || out[len(out)-1].ends()
+}
+
+// repeatAt returns the length of a phrase of up to three words at
+// words[i] that is immediately said again, or 0.
+func repeatAt(words []word, i int, allowed map[string]bool) int {
+	for n := 3; n >= 1; n-- {
+		if i+2*n > len(words) {
+			continue
+		}
+		match := true
+		for k := 0; k < n; k++ {
+			a, b := words[i+k].norm(), words[i+n+k].norm()
+			if a == "" || a != b {
+				match = false
+				break
+			}
+			_, _, trail := words[i+k].split()
+			if strings.ContainsAny(trail, ".!?") {
+				match = false
+				break
+			}
+		}
+		if match && !(n == 1 && allowed[words[i].norm()]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// compilePhrases normalizes a word list, longest phrase first.
+func compilePhrases(list []string) [][]string {
+	var phrases [][]string
+	for _, p := range list {
+		if norm := normalizePhrase(p); norm != "" {
+			phrases = append(phrases, strings.Fields(norm))
+		}
+	}
+	sort.SliceStable(phrases, func(i, j int) bool { return len(phrases[i]) > len(phrases[j]) })
+	return phrases
+}
+
+// phraseAt returns how many words starting at words[i] match one of the
+// phrases, not counting matches that span a clause break.
+func phraseAt(words []word, i int, phrases [][]string) int {
+	for _, phrase := range phrases {
+		if i+len(phrase) > len(words) {
+			continue
+		}
+		match := true
+		for k, w := range phrase {
+			if words[i+k].norm() != w || (k < len(phrase)-1 && words[i+k].ends()) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return len(phrase)
+		}
+	}
+	return 0
+}
+
+// dropWords removes words[i:i+n] and tidies up around the gap: the
+// whitespace before the run moves to the word after it unless that starts
+// a new line, sentence-ending punctuation moves back to the word before
+// it ("it, um." → "it."), and a capital that started the sentence moves
+// to the next word ("Um, so" → "So").
+func dropWords(out, words []word, i, n int) []word {
+	_, _, trail := words[i+n-1].split()
+	sentenceStart := len(out) == 0 || strings.ContainsAny(lastTrail(out), ".!?")
+
+	if end := strings.TrimLeft(trail, ",;:"); len(out) > 0 && strings.ContainsAny(end, ".!?") {
+		prev := &out[len(out)-1]
+		if lead, core, prevTrail := prev.split(); !strings.ContainsAny(prevTrail, ".!?") {
+			prev.text = lead + core + end
+		}
+	}
+
+	if i+n < len(words) {
+		next := &words[i+n]
+		if !strings.Contains(next.sep, "\n") {
+			next.sep = words[i].sep
+		}
+		_, first, _ := words[i].split()
+		if r, _ := utf8.DecodeRuneInString(first); sentenceStart && unicode.IsUpper(r) {
+			next.text = capitalizeFirst(next.text)
+		}
+	}
+	return out
+}
+
+func lastTrail(out []word) string {
+	_, _, trail := out[len(out)-1].split()
+	return trail
+}
+
+func capitalizeFirst(s string) string {
+	lead := strings.TrimLeftFunc(s, unicode.IsPunct)
+	r, size := utf8.DecodeRuneInString(lead)
+	if r == utf8.RuneError {
+		return s
+	}
+	return s[:len(s)-len(lead)] + string(unicode.ToUpper(r)) + lead[size:]
+}
@@ -0,0 +1,72 @@
+package internal
+
+import "testing"
+
+func TestRemoveFillers(t *testing.T) {
+	tests := []struct {
+		name    string
+		lang    string
+		extra   []string
+		repeats bool
+		in      string
+		want    string
+	}{
+		{"fillers", "en", nil, false, "so um we should uh ship it", "so we should ship it"},
+		{"leading filler", "en", nil, false, "Um, let's go", "Let's go"},
+		{"trailing filler after comma", "en", nil, false, "I like it, um.", "I like it."},
+		{"trailing filler", "en", nil, false, "I like it um.", "I like it."},
+		{"keeps question mark", "en", nil, false, "Really? Um.", "Really?"},
+		{"keeps line breaks", "en", nil, false, "line one uh\nline two", "line one\nline two"},
+		{"only fillers", "en", nil, false, "Um, uh.", ""},
+		{"soft filler set off", "en", nil, false, "It's, like, really fast", "It's, really fast"},
+		{"soft filler phrase", "en", nil, false, "Like, you know, it works.", "It works."},
+		{"soft filler as a real word", "en", nil, false, "I like it, you know, a lot", "I like it, a lot"},
+		{"soft filler without commas", "en", nil, false, "you know the answer", "you know the answer"},
+		{"extra words", "en", []string{"basically"}, false, "it basically works um", "it works"},
+		{"repeated word", "en", nil, true, "fix the the bug", "fix the bug"},
+		{"repeated pronoun", "en", nil, true, "I I think so", "I think so"},
+		{"repeated phrase", "en", nil, true, "I think I think we can", "I think we can"},
+		{"false start with comma", "en", nil, true, "The, the build is green", "The build is green"},
+		{"repeat across sentences", "en", nil, true, "Go. Go now", "Go. Go now"},
+		{"allowed double", "en", nil, true, "I know that that works", "I know that that works"},
+		{"repeats off", "en", nil, false, "the the", "the the"},
+		{"spanish", "es", nil, false, "Eh, vamos, o sea, ya", "Vamos, ya"},
+		{"spanish real word", "es", nil, false, "este código funciona", "este código funciona"},
+		{"french real words", "fr", nil, false, "euh eh ben oui, bah non", "eh ben oui, bah non"},
+		{"portuguese real word", "pt", nil, false, "hum a casa é nova", "a casa é nova"},
+		{"german", "de", nil, true, "ähm ich ich glaube", "ich glaube"},
+		{"german allowed double", "de", nil, true, "dass die die Katze", "dass die die Katze"},
+		{"unknown language uses only extra words", "xx", []string{"bla"}, false, "um bla ok", "um ok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RemoveFillers(tt.in, tt.lang, tt.extra, tt.repeats); got != tt.want {
+				t.Errorf("RemoveFillers(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseLanguage(t *testing.T) {
+	for in, want := range map[string]string{"en-US": "en", "pt_BR": "pt", "DE": "de", "": "en"} {
+		if got := baseLanguage(in); got != want {
+			t.Errorf("baseLanguage(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFillerStageUsesSessionLanguage(t *testing.T) {
+	cfg := &Config{Filler: FillerConfig{Enabled: true, Words: []string{"basically"}}}
+	p, err := NewPipeline(cfg)
+	if err != nil {
+		t.Fatalf("NewPipeline: %v", err)
+	}
+	d := &Dictionary{entries: map[string]string{"cube": "kube"}, commands: map[string]string{}}
+	got, err := p.Run("euh basically um cube", Session{Language: "fr-FR", Dict: d})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got != "um kube" {
+		t.Errorf("Run = %q, want French fillers and extra words removed before the dictionary", got)
+	}
+}
@@ -0,0 +1,262 @@
+package internal
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FormatConfig toggles the spoken-form transforms applied to a transcript
+// after dictionary replacement.
+type FormatConfig struct {
+	Casing      bool `toml:"casing"`      // "camel case user id" → userId
+	Numbers     bool `toml:"numbers"`     // "twenty three" → 23
+	Dates       bool `toml:"dates"`       // "march fifth" → March 5
+	Paths       bool `toml:"paths"`       // "src slash main dot go" → src/main.go
+	Identifiers bool `toml:"identifiers"` // "max underscore retries" → max_retries
+}
+
+// word is a whitespace-separated piece of a transcript along with the
+// whitespace before it, so formatting keeps line breaks and spacing that
+// the dictionary inserted.
+type word struct {
+	sep  string
+	text string
+}
+
+func (w word) norm() string { return normalizeWord(w.text) }
+
+// split returns the word's leading punctuation, its core and its trailing
+// punctuation: `"id.` → `"`, `id`, `.`.
+func (w word) split() (lead, core, trail string) {
+	core = strings.TrimLeftFunc(w.text, unicode.IsPunct)
+	lead = w.text[:len(w.text)-len(core)]
+	trimmed := strings.TrimRightFunc(core, unicode.IsPunct)
+	return lead, trimmed, core[len(trimmed):]
+}
+
+// ends reports whether the word closes a clause, so runs of words being
+// formatted together stop there.
+func (w word) ends() bool {
+	_, _, trail := w.split()
+	return trail != ""
+}
+
+func splitWords(text string) (words []word, tail string) {
+	start, wordStart := 0, -1
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			if wordStart >= 0 {
+				words = append(words, word{sep: text[start:wordStart], text: text[wordStart:i]})
+				start, wordStart = i, -1
+			}
+		} else if wordStart < 0 {
+			wordStart = i
+		}
+	}
+	if wordStart >= 0 {
+		words = append(words, word{sep: text[start:wordStart], text: text[wordStart:]})
+		return words, ""
+	}
+	return words, text[start:]
+}
+
+func joinWords(words []word, tail string) string {
+	var b strings.Builder
+	for _, w := range words {
+		b.WriteString(w.sep)
+		b.WriteString(w.text)
+	}
+	b.WriteString(tail)
+	return b.String()
+}
+
+// merge replaces words[i:j] with text, keeping the punctuation around the
+// run and the whitespace before it.
+func merge(words []word, i, j int, text string) word {
+	lead, _, _ := words[i].split()
+	_, _, trail := words[j-1].split()
+	return word{sep: words[i].sep, text: lead + text + trail}
+}
+
+// Format applies the enabled spoken-form transforms to text.
+func Format(text string, cfg FormatConfig) string {
+	words, tail := splitWords(text)
+	if cfg.Dates {
+		words = formatDates(words)
+	}
+	if cfg.Numbers {
+		words = formatNumbers(words)
+	}
+	joins := cfg.Paths || cfg.Identifiers
+	if cfg.Casing {
+		words = formatCasing(words, joins)
+	}
+	if joins {
+		words = formatJoins(words, cfg.Paths, cfg.Identifiers)
+	}
+	return joinWords(words, tail)
+}
+
+// casingStyles maps a spoken casing command to how it joins the words
+// that follow it.
+var casingStyles = map[string]func([]string) string{
+	"camel case": func(ws []string) string {
+		return strings.ToLower(ws[0]) + capitalizeAll(ws[1:])
+	},
+	"pascal case": capitalizeAll,
+	"snake case": func(ws []string) string {
+		return strings.ToLower(strings.Join(ws, "_"))
+	},
+	"kebab case": func(ws []string) string {
+		return strings.ToLower(strings.Join(ws, "-"))
+	},
+	"constant case": func(ws []string) string {
+		return strings.ToUpper(strings.Join(ws, "_"))
+	},
+	"all caps": func(ws []string) string {
+		return strings.ToUpper(strings.Join(ws, " "))
+	},
+	"lower case": func(ws []string) string {
+		return strings.ToLower(strings.Join(ws, " "))
+	},
+}
+
+func capitalizeAll(ws []string) string {
+	var b strings.Builder
+	for _, w := range ws {
+		w = strings.ToLower(w)
+		r, size := utf8.DecodeRuneInString(w)
+		b.WriteRune(unicode.ToUpper(r))
+		b.WriteString(w[size:])
+	}
+	return b.String()
+}
+
+// formatCasing applies casing commands such as "snake case max retries" to
+// the words after them, up to the end of the clause or line. Punctuation
+// the provider puts inside the command ("Camel case, user ID.") is
+// dropped with it. With joins on, a spoken separator also ends the run, so
+// "camel case user store dot go" becomes userStore.go.
+func formatCasing(words []word, joins bool) []word {
+	out := make([]word, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		if i+2 >= len(words) {
+			out = append(out, words[i])
+			continue
+		}
+		style, ok := casingStyles[words[i].norm()+" "+words[i+1].norm()]
+		if !ok {
+			out = append(out, words[i])
+			continue
+		}
+
+		j := i + 2
+		var cores []string
+		for j < len(words) {
+			if j > i+2 && strings.Contains(words[j].sep, "\n") {
+				break
+			}
+			if _, ok := joiners[words[j].norm()]; ok && joins {
+				break
+			}
+			if _, core, _ := words[j].split(); core != "" {
+				cores = append(cores, core)
+			}
+			j++
+			if words[j-1].ends() {
+				break
+			}
+		}
+		if len(cores) == 0 {
+			out = append(out, words[i])
+			continue
+		}
+		w := merge(words, i+2, j, style(cores))
+		lead, _, _ := words[i].split()
+		w.sep, w.text = words[i].sep, lead+w.text
+		out = append(out, w)
+		i = j - 1
+	}
+	return out
+}
+
+// joiners are the spoken separators formatJoins understands.
+var joiners = map[string]string{
+	"slash":      "/",
+	"backslash":  `\`,
+	"dot":        ".",
+	"underscore": "_",
+	"hyphen":     "-",
+}
+
+// formatJoins glues words around spoken separators: with paths on,
+// "slash" and "backslash" ("src slash main dot go" → src/main.go, "dot"
+// only inside a path); with identifiers on, "dot", "underscore" and
+// "hyphen" ("max underscore retries" → max_retries).
+func formatJoins(words []word, paths, identifiers bool) []word {
+	enabled := func(sym, left string) bool {
+		switch sym {
+		case "/", `\`:
+			return paths
+		case ".":
+			return identifiers || (paths && strings.ContainsAny(left, `/\`))
+		default:
+			return identifiers
+		}
+	}
+
+	out := make([]word, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		sym, isJoiner := joiners[w.norm()]
+		if paths && w.norm() == "tilde" && i+1 < len(words) && words[i+1].norm() == "slash" {
+			lead, _, trail := w.split()
+			out = append(out, word{sep: w.sep, text: lead + "~" + trail})
+			continue
+		}
+		if !isJoiner || w.ends() || i+1 >= len(words) || strings.Contains(words[i+1].sep, "\n") {
+			out = append(out, w)
+			continue
+		}
+		if _, next := joiners[words[i+1].norm()]; next {
+			out = append(out, w)
+			continue
+		}
+		_, right, _ := words[i+1].split()
+
+		// A separator with nothing joinable before it starts a path,
+		// e.g. "slash etc slash hosts" → /etc/hosts.
+		if len(out) == 0 || out[len(out)-1].ends() {
+			if (sym == "/" || sym == `\`) && paths {
+				out = append(out, merge(words, i, i+2, sym+uncapitalize(right)))
+				i++
+				continue
+			}
+			out = append(out, w)
+			continue
+		}
+
+		prev := out[len(out)-1]
+		lead, left, _ := prev.split()
+		if !enabled(sym, left) {
+			out = append(out, w)
+			continue
+		}
+		_, _, trail := words[i+1].split()
+		out[len(out)-1] = word{sep: prev.sep, text: lead + uncapitalize(left) + sym + uncapitalize(right) + trail}
+		i++
+	}
+	return out
+}
+
+// uncapitalize undoes the sentence capitalization smart formatting adds
+// ("Src" → "src") but leaves words with other capitals alone.
+func uncapitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	rest := s[size:]
+	if !unicode.IsUpper(r) || strings.ToLower(rest) != rest {
+		return s
+	}
+	return string(unicode.ToLower(r)) + rest
+}
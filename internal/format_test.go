@@ -0,0 +1,75 @@
+package internal
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	all := FormatConfig{Casing: true, Numbers: true, Dates: true, Paths: true, Identifiers: true}
+	tests := []struct {
+		name string
+		cfg  FormatConfig
+		in   string
+		want string
+	}{
+		{"disabled", FormatConfig{}, "camel case user id", "camel case user id"},
+
+		{"camel case", FormatConfig{Casing: true}, "camel case user id", "userId"},
+		{"camel case smart formatted", FormatConfig{Casing: true}, "Camel case, user ID.", "userId."},
+		{"casing command split by period", FormatConfig{Casing: true}, "Snake case. Max retries.", "max_retries."},
+		{"casing command split by comma", FormatConfig{Casing: true}, "okay, camel, case user id, then save", "okay, userId, then save"},
+		{"casing command quoted", FormatConfig{Casing: true}, `set "camel case user id"`, `set "userId"`},
+		{"camel case to clause end", FormatConfig{Casing: true}, "set camel case user id, then save", "set userId, then save"},
+		{"pascal case", FormatConfig{Casing: true}, "pascal case http client", "HttpClient"},
+		{"snake case", FormatConfig{Casing: true}, "snake case max retries", "max_retries"},
+		{"kebab case", FormatConfig{Casing: true}, "kebab case dry run.", "dry-run."},
+		{"constant case", FormatConfig{Casing: true}, "constant case max retries", "MAX_RETRIES"},
+		{"all caps", FormatConfig{Casing: true}, "all caps todo", "TODO"},
+		{"lower case", FormatConfig{Casing: true}, "lower case Hello World", "hello world"},
+		{"casing command alone", FormatConfig{Casing: true}, "camel case", "camel case"},
+		{"casing command alone punctuated", FormatConfig{Casing: true}, "Camel case.", "Camel case."},
+		{"casing stops at line break", FormatConfig{Casing: true}, "snake case max retries\nnext line", "max_retries\nnext line"},
+
+		{"numbers", FormatConfig{Numbers: true}, "wait twenty three seconds", "wait 23 seconds"},
+		{"small number stays", FormatConfig{Numbers: true}, "one of them", "one of them"},
+		{"hundreds", FormatConfig{Numbers: true}, "two hundred and five rows", "205 rows"},
+		{"and without number", FormatConfig{Numbers: true}, "two hundred and more", "200 and more"},
+		{"thousands", FormatConfig{Numbers: true}, "three thousand four hundred twelve", "3412"},
+		{"millions", FormatConfig{Numbers: true}, "two million three thousand", "2003000"},
+		{"decimal", FormatConfig{Numbers: true}, "pi is three point one four", "pi is 3.14"},
+		{"ordinal", FormatConfig{Numbers: true}, "the twenty first line", "the 21st line"},
+		{"small ordinal stays", FormatConfig{Numbers: true}, "the second try", "the second try"},
+		{"teen ordinal", FormatConfig{Numbers: true}, "the twelfth item", "the 12th item"},
+		{"numbers keep punctuation", FormatConfig{Numbers: true}, "Give me fifteen.", "Give me 15."},
+		{"numbers stop at punctuation", FormatConfig{Numbers: true}, "twenty, thirty", "20, 30"},
+		{"digit sequence", FormatConfig{Numbers: true}, "one two three", "one two three"},
+
+		{"date", FormatConfig{Dates: true}, "due march fifth", "due March 5"},
+		{"date with year", FormatConfig{Dates: true}, "on january twenty first twenty twenty six", "on January 21, 2026"},
+		{"date with oh year", FormatConfig{Dates: true}, "may third nineteen oh five", "May 3, 1905"},
+		{"date with full year", FormatConfig{Dates: true}, "june first two thousand and ten.", "June 1, 2010."},
+		{"date already in digits", FormatConfig{Dates: true}, "March 5th, 2026", "March 5, 2026"},
+		{"month as verb", FormatConfig{Dates: true}, "may I march on", "may I march on"},
+
+		{"path", FormatConfig{Paths: true}, "open src slash main dot go", "open src/main.go"},
+		{"absolute path", FormatConfig{Paths: true}, "slash etc slash hosts", "/etc/hosts"},
+		{"home path", FormatConfig{Paths: true}, "tilde slash notes dot md", "~/notes.md"},
+		{"path keeps capitals", FormatConfig{Paths: true}, "Src slash GitHub", "src/GitHub"},
+		{"dot outside path", FormatConfig{Paths: true}, "polka dot dress", "polka dot dress"},
+		{"backslash", FormatConfig{Paths: true}, "c backslash windows", `c\windows`},
+
+		{"identifier underscore", FormatConfig{Identifiers: true}, "max underscore retries", "max_retries"},
+		{"identifier dot", FormatConfig{Identifiers: true}, "call os dot exit", "call os.exit"},
+		{"identifier hyphen", FormatConfig{Identifiers: true}, "dry hyphen run", "dry-run"},
+		{"identifiers leave slash", FormatConfig{Identifiers: true}, "and slash or", "and slash or"},
+		{"joiner at end", FormatConfig{Identifiers: true}, "add an underscore", "add an underscore"},
+
+		{"combined", all, "Open src slash camel case user store dot go on march fifth.", "Open src/userStore.go on March 5."},
+		{"whitespace kept", all, "  twenty one\n\tsnake case a b  ", "  21\n\ta_b  "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Format(tt.in, tt.cfg); got != tt.want {
+				t.Errorf("Format(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// HistoryEntry records one dictation session.
+type HistoryEntry struct {
+	Time      time.Time `json:"time"`
+	Raw       string    `json:"raw"`                 // transcript as received from the provider
+	Delivered string    `json:"delivered,omitempty"` // text handed to the output
+	Output    string    `json:"output,omitempty"`    // output mode it went to
+	Profile   string    `json:"profile,omitempty"`
+	Language  string    `json:"language,omitempty"`  // spoken language, detected when auto
+	Unlisted  bool      `json:"unlisted,omitempty"`  // detected language isn't in languages
+	Cancelled bool      `json:"cancelled,omitempty"` // discarded with the cancel key
+	Dropped   int       `json:"dropped,omitempty"`   // audio frames lost because golos fell behind
+	Gaps      int       `json:"gaps,omitempty"`      // runs of dropped frames
+	Spilled   int       `json:"spilled,omitempty"`   // most bytes of audio waiting for Deepgram at once
+	OnDisk    bool      `json:"on_disk,omitempty"`   // waiting audio overflowed to a temp file
+}
+
+// ErrNoHistory is returned when no session has been recorded yet.
+var ErrNoHistory = errors.New("no transcripts in history yet — set history = true")
+
+func historyPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "golos", "history.jsonl")
+}
+
+// AppendHistory adds an entry to ~/.config/golos/history.jsonl.
+func AppendHistory(e HistoryEntry) error {
+	path := historyPath()
+	_ = os.MkdirAll(filepath.Dir(path), 0700)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// LoadHistory returns all recorded sessions, oldest first. Lines that
+// cannot be parsed are skipped.
+func LoadHistory() ([]HistoryEntry, error) {
+	f, err := os.Open(historyPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []HistoryEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var e HistoryEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
+
+// LastDelivered returns the most recent session that delivered text.
+func LastDelivered() (HistoryEntry, error) {
+	entries, err := LoadHistory()
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Delivered != "" {
+			return entries[i], nil
+		}
+	}
+	return HistoryEntry{}, ErrNoHistory
+}
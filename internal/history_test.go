@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistoryRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if _, err := LastDelivered(); !errors.Is(err, ErrNoHistory) {
+		t.Fatalf("LastDelivered on empty history = %v, want ErrNoHistory", err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	for _, e := range []HistoryEntry{
+		{Time: now, Raw: "deploy to cube", Delivered: "deploy to cube", Output: "clipboard"},
+		{Time: now, Raw: "undo that"}, // command only, nothing delivered
+	} {
+		if err := AppendHistory(e); err != nil {
+			t.Fatalf("AppendHistory: %v", err)
+		}
+	}
+
+	entries, err := LoadHistory()
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if !entries[0].Time.Equal(now) || entries[0].Output != "clipboard" {
+		t.Errorf("entry = %+v", entries[0])
+	}
+
+	last, err := LastDelivered()
+	if err != nil {
+		t.Fatalf("LastDelivered: %v", err)
+	}
+	if last.Delivered != "deploy to cube" {
+		t.Errorf("LastDelivered = %q, want the last session with delivered text", last.Delivered)
+	}
+}
+
+func TestLoadHistorySkipsBadLines(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := historyPath()
+	_ = os.MkdirAll(filepath.Dir(path), 0700)
+	data := "not json\n{\"raw\":\"hi\",\"delivered\":\"hi\"}\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := LoadHistory()
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Raw != "hi" {
+		t.Errorf("entries = %+v, want the one valid line", entries)
+	}
+}
@@ -0,0 +1,274 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Gesture is how a hotkey has to be pressed to start a session.
+type Gesture int
+
+const (
+	GesturePress     Gesture = iota // record while the key is held
+	GestureHold                     // record once the key has been held for a while
+	GestureDoubleTap                // tap, then press again and hold to record
+)
+
+// defaultGestureTime is how long a hold must last, or how quickly a double
+// tap must follow the first tap, when the hotkey doesn't say.
+const defaultGestureTime = 300 * time.Millisecond
+
+// HotkeySpec is a parsed hotkey such as "ctrl+shift+space",
+// "double_tap:right_option" or "hold:fn>300ms". Platform backends report
+// key events and leave modifier and timing checks to the spec.
+type HotkeySpec struct {
+	Chord
+	Gesture  Gesture
+	Duration time.Duration // hold: time to hold; double tap: longest gap between taps
+}
+
+// ParseHotkey parses "[gesture:]chord[>duration]". The gesture is
+// "hold" or "double_tap"; the chord is as for key chords, with the
+// modifier keys themselves (right_option, fn, ...) also usable as keys.
+func ParseHotkey(s string) (HotkeySpec, error) {
+	var spec HotkeySpec
+	rest := strings.ToLower(strings.TrimSpace(s))
+	if gesture, chord, ok := strings.Cut(rest, ":"); ok {
+		switch strings.TrimSpace(gesture) {
+		case "hold":
+			spec.Gesture = GestureHold
+		case "double_tap", "double":
+			spec.Gesture = GestureDoubleTap
+		default:
+			return HotkeySpec{}, fmt.Errorf("unknown gesture %q in hotkey %q (supported: hold, double_tap)", gesture, s)
+		}
+		rest = chord
+	}
+	if chord, d, ok := strings.Cut(rest, ">"); ok {
+		if spec.Gesture == GesturePress {
+			return HotkeySpec{}, fmt.Errorf("hotkey %q: a duration needs the hold or double_tap gesture", s)
+		}
+		dur, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || dur <= 0 {
+			return HotkeySpec{}, fmt.Errorf("hotkey %q: invalid duration %q", s, d)
+		}
+		spec.Duration = dur
+		rest = chord
+	} else if spec.Gesture != GesturePress {
+		spec.Duration = defaultGestureTime
+	}
+
+	ch, err := ParseChord(rest)
+	if err != nil {
+		return HotkeySpec{}, fmt.Errorf("hotkey: %w", err)
+	}
+	spec.Chord = ch
+	return spec, nil
+}
+
+func (s HotkeySpec) String() string {
+	switch s.Gesture {
+	case GestureHold:
+		return "hold:" + s.Chord.String() + ">" + s.Duration.String()
+	case GestureDoubleTap:
+		return "double_tap:" + s.Chord.String() + ">" + s.Duration.String()
+	}
+	return s.Chord.String()
+}
+
+// modifierKeys maps the codes of modifier keys to the modifier they set.
+var modifierKeys = map[int]string{
+	56: "shift", 60: "shift", 59: "ctrl", 62: "ctrl",
+	58: "alt", 61: "alt", 55: "cmd", 54: "cmd",
+	63: "fn", 57: "caps_lock",
+}
+
+// KeyEvent is a key press or release seen by a hotkey backend, with the
+// modifiers held at the time.
+type KeyEvent struct {
+	Chord
+	Down bool
+	Time time.Time
+}
+
+// NewKeyEvent builds an event for a key code. A modifier key's own
+// modifier is left out, so pressing right option reads as "right_option"
+// rather than "alt+right_option".
+func NewKeyEvent(code int, down bool, mods Chord) KeyEvent {
+	switch modifierKeys[code] {
+	case "shift":
+		mods.Shift = false
+	case "ctrl":
+		mods.Ctrl = false
+	case "alt":
+		mods.Alt = false
+	case "cmd":
+		mods.Cmd = false
+	}
+	mods.Key, mods.Code = KeyName(code), code
+	return KeyEvent{Chord: mods, Down: down, Time: time.Now()}
+}
+
+func (e KeyEvent) String() string {
+	if e.Down {
+		return e.Chord.String() + " down"
+	}
+	return e.Chord.String() + " up"
+}
+
+// cancelPending is set while a released session is still being finished
+// and can be cancelled.
+var cancelPending atomic.Bool
+
+// SetCancelPending makes the cancel key cancel even though no hotkey is
+// held, while a released session is waiting for its transcript.
+func SetCancelPending(on bool) {
+	cancelPending.Store(on)
+}
+
+// hotkeyMatcher turns key events into hotkey down and up calls for one
+// spec, and into cancel calls when the cancel key is pressed while the
+// hotkey is held or a released session is pending. Events arrive from the backend's event thread and hold
+// timers fire on their own goroutines, so state is guarded by mu. The
+// callbacks are called with mu held and must not block.
+type hotkeyMatcher struct {
+	spec             HotkeySpec
+	cancelKey        Chord // no Key when cancelling is off
+	ignore           Chord // modifiers that may be held without being in spec
+	down, up, cancel func()
+
+	mu      sync.Mutex
+	held    bool      // the key is down
+	active  bool      // down was called and up wasn't yet
+	presses int       // counts presses, so a stale hold timer can tell
+	pressed time.Time // start of the current press; zero if another key interrupted it
+	tapped  time.Time // end of the last quick tap, for double taps
+	timer   *time.Timer
+}
+
+// newHotkeyMatcher returns a matcher for spec. The modifiers in ignore,
+// such as the polish modifier, may be held with the hotkey without
+// stopping it from matching, as may the modifier of the hotkey's own key.
+func newHotkeyMatcher(spec HotkeySpec, cancelKey, ignore Chord, down, up, cancel func()) *hotkeyMatcher {
+	ignore.setModifier(modifierKeys[spec.Code])
+	return &hotkeyMatcher{spec: spec, cancelKey: cancelKey, ignore: ignore, down: down, up: up, cancel: cancel}
+}
+
+// handle feeds an event to the matcher and reports whether the event
+// belongs to a recognised hotkey press, so the backend can swallow it.
+// Hold and double tap gestures let the key through until recognised.
+func (m *hotkeyMatcher) handle(ev KeyEvent) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ev.Code != m.spec.Code {
+		if (m.active || cancelPending.Load()) && m.isCancel(ev) {
+			if ev.Down {
+				m.cancel()
+			}
+			return true
+		}
+		if ev.Down && !m.ignored(ev.Code) {
+			// Another key pressed in between: not a tap, and not a hold
+			// when the hotkey is a modifier being used to type.
+			m.pressed, m.tapped = time.Time{}, time.Time{}
+			if !m.active {
+				m.stopTimer()
+			}
+		}
+		return false
+	}
+
+	if ev.Down {
+		if m.held {
+			return m.active // key repeat
+		}
+		s := m.spec
+		if !modifierMatches(ev.Ctrl, s.Ctrl, m.ignore.Ctrl) || !modifierMatches(ev.Shift, s.Shift, m.ignore.Shift) ||
+			!modifierMatches(ev.Alt, s.Alt, m.ignore.Alt) || !modifierMatches(ev.Cmd, s.Cmd, m.ignore.Cmd) {
+			m.tapped = time.Time{}
+			return false
+		}
+		m.held = true
+		m.presses++
+		m.pressed = ev.Time
+		switch s.Gesture {
+		case GesturePress:
+			m.fire()
+		case GestureHold:
+			press := m.presses
+			m.timer = time.AfterFunc(s.Duration, func() {
+				m.mu.Lock()
+				defer m.mu.Unlock()
+				if m.presses == press && m.held && !m.active {
+					m.fire()
+				}
+			})
+		case GestureDoubleTap:
+			if !m.tapped.IsZero() && ev.Time.Sub(m.tapped) <= s.Duration {
+				m.fire()
+			}
+			m.tapped = time.Time{}
+		}
+		return m.active
+	}
+
+	if !m.held {
+		return false
+	}
+	m.held = false
+	m.stopTimer()
+	if m.active {
+		m.active = false
+		m.up()
+		return true
+	}
+	if m.spec.Gesture == GestureDoubleTap && !m.pressed.IsZero() && ev.Time.Sub(m.pressed) <= m.spec.Duration {
+		m.tapped = ev.Time
+	}
+	return false
+}
+
+// isCancel reports whether ev is the cancel key. Modifiers held for the
+// hotkey itself don't get in the way.
+func (m *hotkeyMatcher) isCancel(ev KeyEvent) bool {
+	c := m.cancelKey
+	if c.Key == "" || ev.Code != c.Code {
+		return false
+	}
+	if !ev.Down {
+		return true
+	}
+	return (!c.Ctrl || ev.Ctrl) && (!c.Shift || ev.Shift) && (!c.Alt || ev.Alt) && (!c.Cmd || ev.Cmd)
+}
+
+// modifierMatches reports whether a modifier's state fits the spec: held
+// if the spec wants it, and otherwise up unless it is ignored.
+func modifierMatches(held, want, ignore bool) bool {
+	return held == want || (held && ignore)
+}
+
+// ignored reports whether code is a modifier key whose modifier the
+// matcher ignores, so pressing it doesn't interrupt a tap or hold.
+func (m *hotkeyMatcher) ignored(code int) bool {
+	var c Chord
+	if !c.setModifier(modifierKeys[code]) {
+		return false
+	}
+	return (c.Ctrl && m.ignore.Ctrl) || (c.Shift && m.ignore.Shift) || (c.Alt && m.ignore.Alt) || (c.Cmd && m.ignore.Cmd)
+}
+
+func (m *hotkeyMatcher) fire() {
+	m.active = true
+	m.down()
+}
+
+func (m *hotkeyMatcher) stopTimer() {
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+}
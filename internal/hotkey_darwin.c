@@ -1,9 +1,6 @@
 #include "hotkey_darwin.h"
 #include "_cgo_export.h"
 
-static CGKeyCode targetKeyCode = 0;
-static bool keyIsDown = false;
-static CGEventFlags targetFlag = 0;
 static CFMachPortRef tapRef = NULL;
 static CFRunLoopRef mainRunLoop = NULL;
 
@@ -19,55 +16,19 @@ static CGEventRef eventCallback(CGEventTapProxy proxy, CGEventType evType, CGEve
         return event;
     }
 
-    // For modifier keys (Option, Command, Fn), use FlagsChanged events
-    if (targetFlag != 0) {
-        if (evType == kCGEventFlagsChanged) {
-            CGEventFlags flags = CGEventGetFlags(event);
-            bool isPressed = (flags & targetFlag) != 0;
-
-            if (isPressed && !keyIsDown) {
-                keyIsDown = true;
-                goHotkeyDown();
-                return NULL;
-            } else if (!isPressed && keyIsDown) {
-                keyIsDown = false;
-                goHotkeyUp();
-                return NULL;
-            }
-        }
+    if (evType != kCGEventKeyDown && evType != kCGEventKeyUp && evType != kCGEventFlagsChanged) {
         return event;
     }
 
-    // For regular keys, use keyDown/keyUp
+    // Go decides whether the event is part of a hotkey and should be swallowed
     CGKeyCode keyCode = (CGKeyCode)CGEventGetIntegerValueField(event, kCGKeyboardEventKeycode);
-    if (keyCode != targetKeyCode) {
-        return event;
-    }
-
-    if (evType == kCGEventKeyDown) {
-        if (!keyIsDown) {
-            keyIsDown = true;
-            goHotkeyDown();
-        }
+    if (goKeyEvent(keyCode, evType == kCGEventFlagsChanged, evType == kCGEventKeyDown, CGEventGetFlags(event))) {
         return NULL;
     }
-
-    if (evType == kCGEventKeyUp) {
-        if (keyIsDown) {
-            keyIsDown = false;
-            goHotkeyUp();
-        }
-        return NULL;
-    }
-
     return event;
 }
 
-bool startEventTap(CGKeyCode keyCode, CGEventFlags modFlag) {
-    targetKeyCode = keyCode;
-    targetFlag = modFlag;
-    keyIsDown = false;
-
+bool startEventTap(void) {
     CGEventMask mask = CGEventMaskBit(kCGEventKeyDown) |
                        CGEventMaskBit(kCGEventKeyUp) |
                        CGEventMaskBit(kCGEventFlagsChanged);
@@ -11,82 +11,83 @@ import (
 	"sync"
 )
 
-const (
-	KeyRightOption  = 61
-	KeyRightCommand = 54
-	KeyFn           = 63
-	KeyF18          = 79
-	KeyF19          = 80
-)
-
-type HotkeyInfo struct {
-	KeyCode C.CGKeyCode
-	ModFlag C.CGEventFlags
+// deviceFlags maps modifier key codes to the device-dependent flag bits
+// (NX_DEVICE*KEYMASK) that tell the left and right keys apart.
+var deviceFlags = map[int]C.CGEventFlags{
+	56: 0x02, 60: 0x04, // shift
+	59: 0x01, 62: 0x2000, // control
+	58: 0x20, 61: 0x40, // option
+	55: 0x08, 54: 0x10, // command
+	63: C.kCGEventFlagMaskSecondaryFn,
+	57: C.kCGEventFlagMaskAlphaShift,
 }
 
 var (
 	hotkeyMu sync.Mutex
-	onDown   func()
-	onUp     func()
+	matchers []*hotkeyMatcher
+	watcher  func(KeyEvent)
 )
 
-//export goHotkeyDown
-func goHotkeyDown() {
-	hotkeyMu.Lock()
-	fn := onDown
-	hotkeyMu.Unlock()
-	if fn != nil {
-		go fn()
+//export goKeyEvent
+func goKeyEvent(keyCode C.CGKeyCode, flagsChanged, keyDown C.bool, flags C.CGEventFlags) C.bool {
+	code, down := int(keyCode), bool(keyDown)
+	if flagsChanged {
+		mask, ok := deviceFlags[code]
+		if !ok {
+			return false
+		}
+		down = flags&mask != 0
 	}
-}
+	ev := NewKeyEvent(code, down, Chord{
+		Ctrl:  flags&C.kCGEventFlagMaskControl != 0,
+		Shift: flags&C.kCGEventFlagMaskShift != 0,
+		Alt:   flags&C.kCGEventFlagMaskAlternate != 0,
+		Cmd:   flags&C.kCGEventFlagMaskCommand != 0,
+	})
 
-//export goHotkeyUp
-func goHotkeyUp() {
 	hotkeyMu.Lock()
-	fn := onUp
+	ms, w := matchers, watcher
 	hotkeyMu.Unlock()
-	if fn != nil {
-		go fn()
+	if w != nil {
+		w(ev)
 	}
-}
-
-func ResolveHotkey(name string) (HotkeyInfo, error) {
-	switch name {
-	case "right_option", "right_alt":
-		return HotkeyInfo{
-			KeyCode: C.CGKeyCode(KeyRightOption),
-			ModFlag: C.CGEventFlags(C.kCGEventFlagMaskAlternate),
-		}, nil
-	case "right_command", "right_cmd":
-		return HotkeyInfo{
-			KeyCode: C.CGKeyCode(KeyRightCommand),
-			ModFlag: C.CGEventFlags(C.kCGEventFlagMaskCommand),
-		}, nil
-	case "fn":
-		return HotkeyInfo{
-			KeyCode: C.CGKeyCode(KeyFn),
-			ModFlag: C.CGEventFlags(C.kCGEventFlagMaskSecondaryFn),
-		}, nil
-	case "f18":
-		return HotkeyInfo{KeyCode: C.CGKeyCode(KeyF18)}, nil
-	case "f19":
-		return HotkeyInfo{KeyCode: C.CGKeyCode(KeyF19)}, nil
-	default:
-		return HotkeyInfo{}, fmt.Errorf("unknown hotkey: %s (supported: right_option, right_command, fn, f18, f19)", name)
+	swallow := false
+	for _, m := range ms {
+		if m.handle(ev) {
+			swallow = true
+		}
 	}
+	return C.bool(swallow)
 }
 
 func CheckAccessibility() bool {
 	return C.AXIsProcessTrusted() != 0
 }
 
-func ListenHotkey(hk HotkeyInfo, downFn, upFn func()) error {
+// WatchKeys makes ListenHotkeys report every key event to fn as well, for
+// `golos hotkey test`.
+func WatchKeys(fn func(KeyEvent)) {
+	hotkeyMu.Lock()
+	watcher = fn
+	hotkeyMu.Unlock()
+}
+
+// ListenHotkeys listens for several hotkeys at once until StopHotkey is
+// called. The callbacks get the index of the hotkey that was pressed or
+// released, or that was held when cancelKey was pressed. A cancelKey
+// without a key turns cancelling off. The modifiers in ignore may be held
+// with any of the hotkeys.
+func ListenHotkeys(specs []HotkeySpec, cancelKey, ignore Chord, downFn, upFn, cancelFn func(int)) error {
+	ms := make([]*hotkeyMatcher, len(specs))
+	for i, spec := range specs {
+		ms[i] = newHotkeyMatcher(spec, cancelKey, ignore,
+			func() { go downFn(i) }, func() { go upFn(i) }, func() { go cancelFn(i) })
+	}
 	hotkeyMu.Lock()
-	onDown = downFn
-	onUp = upFn
+	matchers = ms
 	hotkeyMu.Unlock()
 
-	if !C.startEventTap(hk.KeyCode, hk.ModFlag) {
+	if !C.startEventTap() {
 		return fmt.Errorf("failed to create event tap — check Accessibility permissions")
 	}
 
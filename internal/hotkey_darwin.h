@@ -5,7 +5,7 @@
 #include <CoreFoundation/CoreFoundation.h>
 #include <stdbool.h>
 
-bool startEventTap(CGKeyCode keyCode, CGEventFlags modFlag);
+bool startEventTap(void);
 void runLoop(void);
 void stopLoop(void);
 
@@ -0,0 +1,243 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHotkey(t *testing.T) {
+	tests := []struct {
+		in   string
+		want HotkeySpec
+	}{
+		{"right_option", HotkeySpec{Chord: Chord{Key: "right_option", Code: 61}}},
+		{"right_alt", HotkeySpec{Chord: Chord{Key: "right_alt", Code: 61}}},
+		{"f19", HotkeySpec{Chord: Chord{Key: "f19", Code: 80}}},
+		{"ctrl+shift+space", HotkeySpec{Chord: Chord{Key: "space", Code: 49, Ctrl: true, Shift: true}}},
+		{"double_tap:right_option", HotkeySpec{Chord: Chord{Key: "right_option", Code: 61}, Gesture: GestureDoubleTap, Duration: 300 * time.Millisecond}},
+		{"hold:fn>500ms", HotkeySpec{Chord: Chord{Key: "fn", Code: 63}, Gesture: GestureHold, Duration: 500 * time.Millisecond}},
+		{"Hold: cmd+key_105 > 1s", HotkeySpec{Chord: Chord{Key: "key_105", Code: 105, Cmd: true}, Gesture: GestureHold, Duration: time.Second}},
+	}
+	for _, tt := range tests {
+		got, err := ParseHotkey(tt.in)
+		if err != nil {
+			t.Errorf("ParseHotkey(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseHotkey(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseHotkeyErrors(t *testing.T) {
+	for _, in := range []string{"", "nosuchkey", "tap:f19", "f19>300ms", "hold:f19>soon", "hold:f19>-1s", "key_999"} {
+		if _, err := ParseHotkey(in); err == nil {
+			t.Errorf("ParseHotkey(%q): expected error", in)
+		}
+	}
+}
+
+func TestHotkeySpecString(t *testing.T) {
+	for _, in := range []string{"right_option", "ctrl+shift+space", "hold:fn>300ms", "double_tap:right_command>250ms"} {
+		spec, err := ParseHotkey(in)
+		if err != nil {
+			t.Fatalf("ParseHotkey(%q): %v", in, err)
+		}
+		if spec.String() != in {
+			t.Errorf("String() = %q, want %q", spec.String(), in)
+		}
+	}
+}
+
+func TestKeyName(t *testing.T) {
+	tests := map[int]string{36: "return", 53: "escape", 61: "right_option", 54: "right_command", 49: "space", 110: "key_110"}
+	for code, want := range tests {
+		if got := KeyName(code); got != want {
+			t.Errorf("KeyName(%d) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestNewKeyEventDropsOwnModifier(t *testing.T) {
+	ev := NewKeyEvent(61, true, Chord{Alt: true, Shift: true})
+	if ev.String() != "shift+right_option down" {
+		t.Errorf("String() = %q, want %q", ev.String(), "shift+right_option down")
+	}
+}
+
+type hotkeyRecorder struct {
+	m      *hotkeyMatcher
+	events []string
+	now    time.Time
+}
+
+func newHotkeyRecorder(t *testing.T, hotkey string) *hotkeyRecorder {
+	t.Helper()
+	return newHotkeyRecorderIgnoring(t, hotkey, Chord{})
+}
+
+func newHotkeyRecorderIgnoring(t *testing.T, hotkey string, ignore Chord) *hotkeyRecorder {
+	t.Helper()
+	spec, err := ParseHotkey(hotkey)
+	if err != nil {
+		t.Fatalf("ParseHotkey: %v", err)
+	}
+	r := &hotkeyRecorder{now: time.Now()}
+	escape, _ := ParseChord("escape")
+	r.m = newHotkeyMatcher(spec, escape, ignore,
+		func() { r.events = append(r.events, "down") },
+		func() { r.events = append(r.events, "up") },
+		func() { r.events = append(r.events, "cancel") })
+	return r
+}
+
+// key sends an event for the key after the given delay.
+func (r *hotkeyRecorder) key(chord string, down bool, after time.Duration) bool {
+	ch, err := ParseChord(chord)
+	if err != nil {
+		panic(err)
+	}
+	r.now = r.now.Add(after)
+	return r.m.handle(KeyEvent{Chord: ch, Down: down, Time: r.now})
+}
+
+func (r *hotkeyRecorder) got() string {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	s := ""
+	for _, e := range r.events {
+		s += e + " "
+	}
+	return s
+}
+
+func TestMatcherPress(t *testing.T) {
+	r := newHotkeyRecorder(t, "ctrl+shift+space")
+	if r.key("ctrl+space", true, 0) || r.key("ctrl+space", false, 0) {
+		t.Error("wrong modifiers should pass through")
+	}
+	if !r.key("ctrl+shift+space", true, 0) || !r.key("ctrl+shift+space", true, 0) {
+		t.Error("hotkey and its repeats should be swallowed")
+	}
+	if !r.key("space", false, 0) {
+		t.Error("release should be swallowed even with the modifiers up")
+	}
+	if got := r.got(); got != "down up " {
+		t.Errorf("events = %q, want down up", got)
+	}
+}
+
+func TestMatcherIgnoresPolishModifier(t *testing.T) {
+	shift, _ := ParseModifiers("shift")
+	r := newHotkeyRecorderIgnoring(t, "right_option", shift)
+	if !r.key("shift+right_option", true, 0) || !r.key("right_option", false, 0) {
+		t.Error("hotkey with the polish modifier held should be swallowed")
+	}
+	if r.key("ctrl+right_option", true, 0) || r.key("right_option", false, 0) {
+		t.Error("other modifiers should still pass through")
+	}
+	if got := r.got(); got != "down up " {
+		t.Errorf("events = %q, want down up", got)
+	}
+
+	// Pressing the modifier during a hold doesn't interrupt it.
+	ms := time.Millisecond
+	h := newHotkeyRecorderIgnoring(t, "hold:right_option>20ms", shift)
+	h.key("right_option", true, 0)
+	h.key("left_shift", true, 10*ms)
+	time.Sleep(60 * ms)
+	if got := h.got(); got != "down " {
+		t.Errorf("hold events = %q, want down", got)
+	}
+}
+
+func TestMatcherDoubleTap(t *testing.T) {
+	r := newHotkeyRecorder(t, "double_tap:right_option>300ms")
+	ms := time.Millisecond
+
+	r.key("right_option", true, 0)
+	r.key("right_option", false, 100*ms)
+	if got := r.got(); got != "" {
+		t.Fatalf("single tap fired: %q", got)
+	}
+	if !r.key("right_option", true, 100*ms) {
+		t.Error("second press should be swallowed")
+	}
+	r.key("right_option", false, 2*time.Second)
+	if got := r.got(); got != "down up " {
+		t.Errorf("events = %q, want down up", got)
+	}
+
+	// Too slow between taps.
+	r.key("right_option", true, time.Second)
+	r.key("right_option", false, 100*ms)
+	r.key("right_option", true, 500*ms)
+	r.key("right_option", false, 100*ms)
+	// A key typed between the taps.
+	r.key("right_option", true, time.Second)
+	r.key("right_option", false, 100*ms)
+	r.key("a", true, 10*ms)
+	r.key("right_option", true, 10*ms)
+	r.key("right_option", false, 100*ms)
+	if got := r.got(); got != "down up " {
+		t.Errorf("events = %q, want no more", got)
+	}
+}
+
+func TestMatcherHold(t *testing.T) {
+	r := newHotkeyRecorder(t, "hold:fn>20ms")
+
+	// Released too soon.
+	r.key("fn", true, 0)
+	r.key("fn", false, 0)
+	time.Sleep(60 * time.Millisecond)
+	if got := r.got(); got != "" {
+		t.Fatalf("short press fired: %q", got)
+	}
+
+	// Interrupted by another key.
+	r.key("fn", true, 0)
+	r.key("left", true, 0)
+	time.Sleep(60 * time.Millisecond)
+	r.key("fn", false, 0)
+	if got := r.got(); got != "" {
+		t.Fatalf("interrupted hold fired: %q", got)
+	}
+
+	r.key("fn", true, 0)
+	time.Sleep(60 * time.Millisecond)
+	if !r.key("fn", false, 0) {
+		t.Error("release after a hold should be swallowed")
+	}
+	if got := r.got(); got != "down up " {
+		t.Errorf("events = %q, want down up", got)
+	}
+}
+
+func TestMatcherCancel(t *testing.T) {
+	r := newHotkeyRecorder(t, "right_option")
+	if r.key("escape", true, 0) || r.key("escape", false, 0) {
+		t.Error("escape should pass through while the hotkey is up")
+	}
+	r.key("right_option", true, 0)
+	if !r.key("alt+escape", true, 0) || !r.key("alt+escape", false, 0) {
+		t.Error("escape should be swallowed while the hotkey is held")
+	}
+	r.key("right_option", false, 0)
+	if got := r.got(); got != "down cancel up " {
+		t.Errorf("events = %q, want down cancel up", got)
+	}
+}
+
+func TestMatcherCancelPending(t *testing.T) {
+	r := newHotkeyRecorder(t, "right_option")
+	SetCancelPending(true)
+	defer SetCancelPending(false)
+	if !r.key("escape", true, 0) || !r.key("escape", false, 0) {
+		t.Error("escape should be swallowed while a released session is pending")
+	}
+	if got := r.got(); got != "cancel " {
+		t.Errorf("events = %q, want cancel", got)
+	}
+}
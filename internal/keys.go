@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// keyCodes maps key names to macOS virtual key codes (kVK_* in
+// HIToolbox/Events.h). The names are shared by key chords and hotkeys.
+var keyCodes = map[string]int{
+	"a": 0, "s": 1, "d": 2, "f": 3, "h": 4, "g": 5, "z": 6, "x": 7,
+	"c": 8, "v": 9, "b": 11, "q": 12, "w": 13, "e": 14, "r": 15, "y": 16,
+	"t": 17, "1": 18, "2": 19, "3": 20, "4": 21, "6": 22, "5": 23, "=": 24,
+	"9": 25, "7": 26, "-": 27, "8": 28, "0": 29, "]": 30, "o": 31, "u": 32,
+	"[": 33, "i": 34, "p": 35, "l": 37, "j": 38, "'": 39, "k": 40, ";": 41,
+	"\\": 42, ",": 43, "/": 44, "n": 45, "m": 46, ".": 47, "`": 50,
+
+	"return": 36, "enter": 36, "tab": 48, "space": 49, "backspace": 51,
+	"escape": 53, "esc": 53, "forward_delete": 117, "home": 115, "end": 119,
+	"page_up": 116, "page_down": 121, "left": 123, "right": 124, "down": 125,
+	"up": 126,
+
+	"f1": 122, "f2": 120, "f3": 99, "f4": 118, "f5": 96, "f6": 97, "f7": 98,
+	"f8": 100, "f9": 101, "f10": 109, "f11": 103, "f12": 111, "f13": 105,
+	"f14": 107, "f15": 113, "f16": 106, "f17": 64, "f18": 79, "f19": 80,
+	"f20": 90,
+
+	"left_shift": 56, "right_shift": 60, "left_control": 59, "left_ctrl": 59,
+	"right_control": 62, "right_ctrl": 62, "left_option": 58, "left_alt": 58,
+	"right_option": 61, "right_alt": 61, "left_command": 55, "left_cmd": 55,
+	"right_command": 54, "right_cmd": 54, "fn": 63, "caps_lock": 57,
+}
+
+// lookupKey returns the key code for a key name. Keys without a name can
+// be given by code as "key_<code>", as `golos hotkey test` prints them.
+func lookupKey(name string) (int, bool) {
+	if code, ok := keyCodes[name]; ok {
+		return code, true
+	}
+	if n, ok := strings.CutPrefix(name, "key_"); ok {
+		code, err := strconv.Atoi(n)
+		return code, err == nil && code >= 0 && code < 128
+	}
+	return 0, false
+}
+
+// KeyName returns the name of a key code. Where a key has several names
+// the longest, least abbreviated one is used ("return", "right_option").
+func KeyName(code int) string {
+	name := ""
+	for n, c := range keyCodes {
+		if c == code && (len(n) > len(name) || len(n) == len(name) && n < name) {
+			name = n
+		}
+	}
+	if name == "" {
+		return "key_" + strconv.Itoa(code)
+	}
+	return name
+}
+
+// Chord is a key press with optional modifiers, e.g. "ctrl+c" or
+// "shift+enter".
+type Chord struct {
+	Key   string
+	Code  int
+	Ctrl  bool
+	Shift bool
+	Alt   bool
+	Cmd   bool
+}
+
+// ParseChord parses a "+"-separated chord such as "ctrl+c", "cmd+shift+k"
+// or "escape". The last element is the key; the rest are modifiers.
+func ParseChord(s string) (Chord, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(s)), "+")
+	var ch Chord
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if i == len(parts)-1 {
+			code, ok := lookupKey(part)
+			if !ok {
+				return Chord{}, fmt.Errorf("unknown key %q in %q", part, s)
+			}
+			ch.Key = part
+			ch.Code = code
+			break
+		}
+		if !ch.setModifier(part) {
+			return Chord{}, fmt.Errorf("unknown modifier %q in %q", part, s)
+		}
+	}
+	return ch, nil
+}
+
+// ParseModifiers parses modifiers alone, such as "shift" or "ctrl+alt",
+// into a Chord without a key.
+func ParseModifiers(s string) (Chord, error) {
+	var ch Chord
+	for _, part := range strings.Split(strings.ToLower(strings.TrimSpace(s)), "+") {
+		if part = strings.TrimSpace(part); !ch.setModifier(part) {
+			return Chord{}, fmt.Errorf("unknown modifier %q in %q (supported: ctrl, shift, alt, cmd)", part, s)
+		}
+	}
+	return ch, nil
+}
+
+func (c *Chord) setModifier(name string) bool {
+	switch name {
+	case "ctrl", "control":
+		c.Ctrl = true
+	case "shift":
+		c.Shift = true
+	case "alt", "opt", "option":
+		c.Alt = true
+	case "cmd", "command", "super":
+		c.Cmd = true
+	default:
+		return false
+	}
+	return true
+}
+
+func (c Chord) String() string {
+	var parts []string
+	if c.Ctrl {
+		parts = append(parts, "ctrl")
+	}
+	if c.Alt {
+		parts = append(parts, "alt")
+	}
+	if c.Shift {
+		parts = append(parts, "shift")
+	}
+	if c.Cmd {
+		parts = append(parts, "cmd")
+	}
+	return strings.Join(append(parts, c.Key), "+")
+}
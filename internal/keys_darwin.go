@@ -0,0 +1,66 @@
+package internal
+
+/*
+#cgo LDFLAGS: -framework ApplicationServices
+#include <ApplicationServices/ApplicationServices.h>
+#include <unistd.h>
+
+void postKey(CGKeyCode code, CGEventFlags flags) {
+    CGEventRef down = CGEventCreateKeyboardEvent(NULL, code, true);
+    CGEventSetFlags(down, flags);
+    CGEventPost(kCGHIDEventTap, down);
+    CFRelease(down);
+    usleep(5000);
+
+    CGEventRef up = CGEventCreateKeyboardEvent(NULL, code, false);
+    CGEventSetFlags(up, flags);
+    CGEventPost(kCGHIDEventTap, up);
+    CFRelease(up);
+}
+*/
+import "C"
+
+import "time"
+
+func chordFlags(ch Chord) C.CGEventFlags {
+	var flags C.CGEventFlags
+	if ch.Ctrl {
+		flags |= C.kCGEventFlagMaskControl
+	}
+	if ch.Shift {
+		flags |= C.kCGEventFlagMaskShift
+	}
+	if ch.Alt {
+		flags |= C.kCGEventFlagMaskAlternate
+	}
+	if ch.Cmd {
+		flags |= C.kCGEventFlagMaskCommand
+	}
+	return flags
+}
+
+// SendChord posts the chord to the focused application.
+func SendChord(ch Chord) error {
+	C.postKey(C.CGKeyCode(ch.Code), chordFlags(ch))
+	return nil
+}
+
+// ModifiersHeld reports whether all of the chord's modifiers are held
+// down right now.
+func ModifiersHeld(ch Chord) bool {
+	want := chordFlags(ch)
+	held := C.CGEventSourceFlagsState(C.kCGEventSourceStateCombinedSessionState)
+	return held&want == want
+}
+
+// DeleteBackward sends n Backspace presses to the focused application.
+func DeleteBackward(n int) error {
+	bs := Chord{Key: "backspace", Code: keyCodes["backspace"]}
+	for i := 0; i < n; i++ {
+		if err := SendChord(bs); err != nil {
+			return err
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	return nil
+}
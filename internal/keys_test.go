@@ -0,0 +1,56 @@
+package internal
+
+import "testing"
+
+func TestParseChord(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Chord
+	}{
+		{"escape", Chord{Key: "escape", Code: 53}},
+		{"ctrl+c", Chord{Key: "c", Code: 8, Ctrl: true}},
+		{"Shift+Enter", Chord{Key: "enter", Code: 36, Shift: true}},
+		{"cmd+shift+k", Chord{Key: "k", Code: 40, Cmd: true, Shift: true}},
+		{"option+f19", Chord{Key: "f19", Code: 80, Alt: true}},
+	}
+	for _, tt := range tests {
+		got, err := ParseChord(tt.in)
+		if err != nil {
+			t.Errorf("ParseChord(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseChord(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseChordErrors(t *testing.T) {
+	for _, in := range []string{"", "ctrl+", "hyper+c", "ctrl+nosuchkey"} {
+		if _, err := ParseChord(in); err == nil {
+			t.Errorf("ParseChord(%q): expected error", in)
+		}
+	}
+}
+
+func TestChordString(t *testing.T) {
+	ch, _ := ParseChord("shift+ctrl+c")
+	if ch.String() != "ctrl+shift+c" {
+		t.Errorf("String() = %q, want %q", ch.String(), "ctrl+shift+c")
+	}
+}
+
+func TestParseModifiers(t *testing.T) {
+	ch, err := ParseModifiers("Ctrl + shift")
+	if err != nil {
+		t.Fatalf("ParseModifiers: %v", err)
+	}
+	if !ch.Ctrl || !ch.Shift || ch.Alt || ch.Cmd || ch.Key != "" {
+		t.Errorf("ParseModifiers = %+v, want ctrl+shift", ch)
+	}
+	for _, in := range []string{"", "shift+k", "hyper"} {
+		if _, err := ParseModifiers(in); err == nil {
+			t.Errorf("ParseModifiers(%q): expected error", in)
+		}
+	}
+}
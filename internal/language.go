@@ -0,0 +1,63 @@
+package internal
+
+// AutoLanguage is the language setting that has the provider detect the
+// spoken language. Deepgram streams it with its multilingual model.
+const AutoLanguage = "auto"
+
+// deepgramMulti is Deepgram's multilingual (code-switching) language.
+const deepgramMulti = "multi"
+
+// IsAutoLanguage reports whether a language setting asks for detection.
+func IsAutoLanguage(lang string) bool {
+	return lang == AutoLanguage || lang == deepgramMulti
+}
+
+// pickLanguage chooses the language of a result from the languages the
+// provider reported, most prevalent first. With an allowed list, the
+// first reported language on it wins, in the list's spelling; if none
+// is, the most prevalent one is kept so the label matches the transcript.
+func pickLanguage(reported, allowed []string) string {
+	for _, r := range reported {
+		for _, a := range allowed {
+			if baseLanguage(r) == baseLanguage(a) {
+				return a
+			}
+		}
+	}
+	if len(reported) == 0 {
+		return ""
+	}
+	return reported[0]
+}
+
+// Unlisted reports whether a detected language is missing from the
+// configured languages, which usually means the detection went wrong.
+func Unlisted(lang string, languages []string) bool {
+	if lang == "" || len(languages) == 0 {
+		return false
+	}
+	for _, l := range languages {
+		if baseLanguage(l) == baseLanguage(lang) {
+			return false
+		}
+	}
+	return true
+}
+
+// DominantLanguage returns the most frequent language, the earliest on
+// ties, ignoring empty entries.
+func DominantLanguage(langs []string) string {
+	counts := map[string]int{}
+	for _, l := range langs {
+		if l != "" {
+			counts[l]++
+		}
+	}
+	best := ""
+	for _, l := range langs {
+		if counts[l] > counts[best] {
+			best = l
+		}
+	}
+	return best
+}
@@ -0,0 +1,65 @@
+package internal
+
+import "testing"
+
+func TestPickLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		reported []string
+		allowed  []string
+		want     string
+	}{
+		{"nothing reported", nil, nil, ""},
+		{"no allowed list", []string{"de", "en"}, nil, "de"},
+		{"first allowed wins", []string{"fr", "es", "en"}, []string{"en", "es"}, "es"},
+		{"allowed spelling kept", []string{"es"}, []string{"en-US", "es-ES"}, "es-ES"},
+		{"none allowed", []string{"it"}, []string{"en", "de"}, "it"},
+		{"nothing reported with allowed", nil, []string{"de"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickLanguage(tt.reported, tt.allowed); got != tt.want {
+				t.Errorf("pickLanguage(%v, %v) = %q, want %q", tt.reported, tt.allowed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnlisted(t *testing.T) {
+	languages := []string{"en-US", "es"}
+	for lang, want := range map[string]bool{"en": false, "es-MX": false, "fr": true, "": false} {
+		if got := Unlisted(lang, languages); got != want {
+			t.Errorf("Unlisted(%q) = %v, want %v", lang, got, want)
+		}
+	}
+	if Unlisted("fr", nil) {
+		t.Error("every language is listed without a list")
+	}
+}
+
+func TestDominantLanguage(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"", ""}, ""},
+		{[]string{"", "", "", "en"}, "en"},
+		{[]string{"en", "es", "es"}, "es"},
+		{[]string{"de", "en"}, "de"},
+		{[]string{"", "en", "de", "de", "en"}, "en"},
+	}
+	for _, tt := range tests {
+		if got := DominantLanguage(tt.in); got != tt.want {
+			t.Errorf("DominantLanguage(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIsAutoLanguage(t *testing.T) {
+	for lang, want := range map[string]bool{"auto": true, "multi": true, "en-US": false, "": false} {
+		if got := IsAutoLanguage(lang); got != want {
+			t.Errorf("IsAutoLanguage(%q) = %v, want %v", lang, got, want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SourcePersonal marks entries from the user's own dictionary.toml.
+const SourcePersonal = "personal"
+
+// Layer is a read-only set of dictionary entries merged with the personal
+// dictionary when matching, such as the team or a project dictionary.
+type Layer struct {
+	Source   string
+	Words    map[string]string
+	Commands map[string]string
+}
+
+// Entry is a dictionary phrase as seen after merging all layers.
+type Entry struct {
+	Phrase  string `json:"phrase"`
+	Value   string `json:"value"`
+	Command bool   `json:"command,omitempty"`
+	Source  string `json:"source"`
+}
+
+// WithLayer returns a copy of the dictionary with l layered on top of
+// everything it already holds. The copy is meant for matching only and must
+// not be saved.
+func (d *Dictionary) WithLayer(l Layer) *Dictionary {
+	o := d.clone()
+	o.above = append(append([]Layer(nil), o.above...), l)
+	return o
+}
+
+// sharedWords returns the words of a dictionary someone else wrote, a
+// project's or the team's, that only insert plain text. Replacements with
+// a control character or {clipboard} are dropped: in tmux or type mode a
+// line break presses Return, so they could run whatever they spell out.
+func sharedWords(words map[string]string) map[string]string {
+	out := make(map[string]string, len(words))
+	for k, v := range words {
+		if strings.Contains(v, "{clipboard}") || strings.ContainsFunc(v, unicode.IsControl) {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// clone returns a copy of the dictionary for matching.
+func (d *Dictionary) clone() *Dictionary {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	o := &Dictionary{
+		entries:      make(map[string]string, len(d.entries)),
+		commands:     make(map[string]string, len(d.commands)),
+		below:        d.below,
+		above:        d.above,
+		templateVars: d.templateVars,
+		dir:          d.dir,
+	}
+	for k, v := range d.entries {
+		o.entries[k] = v
+	}
+	for k, v := range d.commands {
+		o.commands[k] = v
+	}
+	return o
+}
+
+// setBelow replaces the layer below the personal entries that has the same
+// source, or adds it. An empty layer is dropped.
+func (d *Dictionary) setBelow(l Layer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	below := d.below[:0:0]
+	for _, b := range d.below {
+		if b.Source != l.Source {
+			below = append(below, b)
+		}
+	}
+	if len(l.Words) > 0 || len(l.Commands) > 0 {
+		below = append(below, l)
+	}
+	d.below = below
+}
+
+// mergedEntries applies the layers from bottom to top. A phrase in a higher
+// layer replaces every punctuation or casing variant of it below. Caller
+// must hold d.mu.
+func (d *Dictionary) mergedEntries() (words, commands map[string]Entry) {
+	words = make(map[string]Entry)
+	commands = make(map[string]Entry)
+
+	apply := func(dst map[string]Entry, src map[string]string, source string, command bool) {
+		norms := make(map[string]bool, len(src))
+		for k := range src {
+			norms[normalizePhrase(k)] = true
+		}
+		for k := range dst {
+			if norms[normalizePhrase(k)] {
+				delete(dst, k)
+			}
+		}
+		for k, v := range src {
+			dst[k] = Entry{Phrase: k, Value: v, Command: command, Source: source}
+		}
+	}
+
+	layers := make([]Layer, 0, len(d.below)+1+len(d.above))
+	layers = append(layers, d.below...)
+	layers = append(layers, Layer{Source: SourcePersonal, Words: d.entries, Commands: d.commands})
+	layers = append(layers, d.above...)
+	for _, l := range layers {
+		apply(words, l.Words, l.Source, false)
+		apply(commands, l.Commands, l.Source, true)
+	}
+	return words, commands
+}
+
+// merged returns the phrase → value maps of all layers combined. Caller
+// must hold d.mu.
+func (d *Dictionary) merged() (words, commands map[string]string) {
+	if len(d.below) == 0 && len(d.above) == 0 {
+		return d.entries, d.commands
+	}
+	we, ce := d.mergedEntries()
+	words = make(map[string]string, len(we))
+	for k, e := range we {
+		words[k] = e.Value
+	}
+	commands = make(map[string]string, len(ce))
+	for k, e := range ce {
+		commands[k] = e.Value
+	}
+	return words, commands
+}
+
+// Entries returns every effective entry with the layer it comes from,
+// words before commands, each sorted by phrase.
+func (d *Dictionary) Entries() []Entry {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	words, commands := d.mergedEntries()
+
+	out := make([]Entry, 0, len(words)+len(commands))
+	for _, m := range []map[string]Entry{words, commands} {
+		start := len(out)
+		for _, e := range m {
+			out = append(out, e)
+		}
+		part := out[start:]
+		sort.Slice(part, func(i, j int) bool {
+			return strings.Compare(part[i].Phrase, part[j].Phrase) < 0
+		})
+	}
+	return out
+}
@@ -0,0 +1,305 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	smallNumbers = map[string]int64{
+		"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+		"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+		"eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14, "fifteen": 15,
+		"sixteen": 16, "seventeen": 17, "eighteen": 18, "nineteen": 19,
+	}
+	tensNumbers = map[string]int64{
+		"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50,
+		"sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+	}
+	scaleNumbers = map[string]int64{
+		"thousand": 1_000, "million": 1_000_000, "billion": 1_000_000_000,
+	}
+	ordinalWords = map[string]string{
+		"first": "one", "second": "two", "third": "three", "fourth": "four",
+		"fifth": "five", "sixth": "six", "seventh": "seven", "eighth": "eight",
+		"ninth": "nine", "tenth": "ten", "eleventh": "eleven", "twelfth": "twelve",
+		"thirteenth": "thirteen", "fourteenth": "fourteen", "fifteenth": "fifteen",
+		"sixteenth": "sixteen", "seventeenth": "seventeen", "eighteenth": "eighteen",
+		"nineteenth": "nineteen", "twentieth": "twenty", "thirtieth": "thirty",
+		"fortieth": "forty", "fiftieth": "fifty", "sixtieth": "sixty",
+		"seventieth": "seventy", "eightieth": "eighty", "ninetieth": "ninety",
+		"hundredth": "hundred", "thousandth": "thousand", "millionth": "million",
+		"billionth": "billion",
+	}
+	months = []string{
+		"january", "february", "march", "april", "may", "june", "july",
+		"august", "september", "october", "november", "december",
+	}
+)
+
+// spokenNumber is a number read from words by parseNumber.
+type spokenNumber struct {
+	value    int64
+	decimals string // digits after "point", if any
+	ordinal  bool
+	used     int // words consumed
+}
+
+func (n spokenNumber) String() string {
+	s := strconv.FormatInt(n.value, 10)
+	if n.decimals != "" {
+		return s + "." + n.decimals
+	}
+	if n.ordinal {
+		return s + ordinalSuffix(n.value)
+	}
+	return s
+}
+
+func ordinalSuffix(n int64) string {
+	if n%100 >= 11 && n%100 <= 13 {
+		return "th"
+	}
+	switch n % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
+
+// parseNumber reads a spoken cardinal ("two hundred and five"), ordinal
+// ("twenty first") or decimal ("three point one four") starting at
+// words[i]. It does not read past punctuation.
+func parseNumber(words []word, i int) (spokenNumber, bool) {
+	const (
+		none = iota
+		unit
+		teen
+		tens
+		hundred
+		scale
+	)
+	var total, current int64
+	last := none
+	n := spokenNumber{}
+
+	j := i
+	for ; j < len(words); j++ {
+		if j > i && words[j-1].ends() {
+			break
+		}
+		w := words[j].norm()
+		cardinal, ordinal := ordinalWords[w]
+		if ordinal {
+			w = cardinal
+		}
+
+		consumed := true
+		v, isSmall := smallNumbers[w]
+		tv, isTens := tensNumbers[w]
+		sv, isScale := scaleNumbers[w]
+		switch {
+		case w == "zero":
+			consumed = last == none
+			if consumed {
+				last = unit
+			}
+		case isSmall && v < 10 && (last == none || last == tens || last == hundred || last == scale):
+			current += v
+			last = unit
+		case isSmall && v >= 10 && (last == none || last == hundred || last == scale):
+			current += v
+			last = teen
+		case isTens && (last == none || last == hundred || last == scale):
+			current += tv
+			last = tens
+		case w == "hundred" && (last == unit || last == teen || last == tens) && current < 100:
+			current *= 100
+			last = hundred
+		case isScale && last != none && last != scale && (total == 0 || total > sv):
+			total += current * sv
+			current = 0
+			last = scale
+		case w == "and" && !ordinal && (last == hundred || last == scale) && j+1 < len(words):
+			// "one hundred and five": only take "and" when a number follows.
+			next := words[j+1].norm()
+			_, small := smallNumbers[next]
+			_, ten := tensNumbers[next]
+			consumed = small || ten
+		default:
+			consumed = false
+		}
+		if !consumed {
+			break
+		}
+		if ordinal {
+			// An ordinal word ends the number.
+			n.ordinal = true
+			j++
+			break
+		}
+	}
+	if last == none {
+		return n, false
+	}
+	if n.ordinal {
+		n.value, n.used = total+current, j-i
+		return n, true
+	}
+
+	n.value, n.used = total+current, j-i
+	if j+1 < len(words) && words[j].norm() == "point" && !words[j-1].ends() && !words[j].ends() {
+		var digits strings.Builder
+		k := j + 1
+		for ; k < len(words); k++ {
+			v, ok := smallNumbers[words[k].norm()]
+			if !ok || v > 9 {
+				break
+			}
+			digits.WriteByte(byte('0' + v))
+			if words[k].ends() {
+				k++
+				break
+			}
+		}
+		if digits.Len() > 0 {
+			n.decimals = digits.String()
+			n.used = k - i
+		}
+	}
+	return n, true
+}
+
+// formatNumbers turns spoken numbers into digits. Single number words
+// below ten are left spelled out, as in prose ("one of them").
+func formatNumbers(words []word) []word {
+	out := make([]word, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		n, ok := parseNumber(words, i)
+		if !ok || (n.used == 1 && n.value < 10) {
+			out = append(out, words[i])
+			continue
+		}
+		out = append(out, merge(words, i, i+n.used, n.String()))
+		i += n.used - 1
+	}
+	return out
+}
+
+// parseDay reads a day of the month, spoken ("fifth", "twenty first") or
+// already formatted ("5th", "21").
+func parseDay(words []word, i int) (int64, int, bool) {
+	if i >= len(words) {
+		return 0, 0, false
+	}
+	_, core, _ := words[i].split()
+	digits := strings.TrimRightFunc(core, unicode.IsLetter)
+	if v, err := strconv.ParseInt(digits, 10, 64); err == nil && v >= 1 && v <= 31 {
+		if suffix := core[len(digits):]; suffix == "" || suffix == ordinalSuffix(v) {
+			return v, 1, true
+		}
+	}
+	n, ok := parseNumber(words, i)
+	if !ok || n.decimals != "" || n.value < 1 || n.value > 31 {
+		return 0, 0, false
+	}
+	return n.value, n.used, true
+}
+
+// parseYear reads a year, either as digits or spoken in pairs ("twenty
+// twenty six", "nineteen oh five") or in full ("two thousand and ten").
+func parseYear(words []word, i int) (int64, int, bool) {
+	if i >= len(words) {
+		return 0, 0, false
+	}
+	_, core, _ := words[i].split()
+	if len(core) == 4 {
+		if v, err := strconv.ParseInt(core, 10, 64); err == nil {
+			return v, 1, true
+		}
+	}
+
+	if hi, used, ok := parseTwoDigits(words, i); ok && hi >= 10 && !words[i+used-1].ends() {
+		j := i + used
+		if j < len(words) {
+			switch words[j].norm() {
+			case "hundred":
+				return hi * 100, used + 1, true
+			case "oh":
+				if j+1 < len(words) && !words[j].ends() {
+					if v, ok := smallNumbers[words[j+1].norm()]; ok && v > 0 && v < 10 {
+						return hi*100 + v, used + 2, true
+					}
+				}
+			default:
+				if lo, n, ok := parseTwoDigits(words, j); ok && lo >= 10 {
+					return hi*100 + lo, used + n, true
+				}
+			}
+		}
+	}
+
+	if n, ok := parseNumber(words, i); ok && !n.ordinal && n.decimals == "" && n.value >= 1000 && n.value < 3000 {
+		return n.value, n.used, true
+	}
+	return 0, 0, false
+}
+
+// parseTwoDigits reads a spoken number from ten to ninety nine.
+func parseTwoDigits(words []word, i int) (int64, int, bool) {
+	w := words[i].norm()
+	if v, ok := smallNumbers[w]; ok && v >= 10 {
+		return v, 1, true
+	}
+	v, ok := tensNumbers[w]
+	if !ok {
+		return 0, 0, false
+	}
+	if i+1 < len(words) && !words[i].ends() {
+		if u, ok := smallNumbers[words[i+1].norm()]; ok && u > 0 && u < 10 {
+			return v + u, 2, true
+		}
+	}
+	return v, 1, true
+}
+
+// formatDates turns a month followed by a day, and optionally a year, into
+// "March 5" or "March 5, 2026". A month name without a day is left alone,
+// so "may" and "march" as verbs are unaffected.
+func formatDates(words []word) []word {
+	out := make([]word, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		month := -1
+		for m, name := range months {
+			if words[i].norm() == name {
+				month = m
+			}
+		}
+		if month < 0 || words[i].ends() {
+			out = append(out, words[i])
+			continue
+		}
+		day, used, ok := parseDay(words, i+1)
+		if !ok {
+			out = append(out, words[i])
+			continue
+		}
+		j := i + 1 + used
+		name := strings.ToUpper(months[month][:1]) + months[month][1:]
+		text := name + " " + strconv.FormatInt(day, 10)
+		if !words[j-1].ends() {
+			if year, n, ok := parseYear(words, j); ok {
+				text += ", " + strconv.FormatInt(year, 10)
+				j += n
+			}
+		}
+		out = append(out, merge(words, i, j, text))
+		i = j - 1
+	}
+	return out
+}
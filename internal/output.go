@@ -5,3 +5,10 @@ type OutputMode interface {
 	// Deliver outputs the transcribed text.
 	Deliver(text string) error
 }
+
+// Submitter is implemented by output modes that can submit delivered text,
+// e.g. by pressing Return in the focused application.
+type Submitter interface {
+	// Submit sends the delivered text, as if the user pressed Return.
+	Submit() error
+}
@@ -19,3 +19,24 @@ func TestStdoutModeDeliver(t *testing.T) {
 		t.Errorf("Deliver error: %v", err)
 	}
 }
+
+func TestTypeModeImplementsOutputMode(t *testing.T) {
+	var _ OutputMode = &TypeMode{}
+}
+
+func TestTmuxModeImplementsOutputMode(t *testing.T) {
+	var _ OutputMode = &TmuxMode{}
+}
+
+func TestSubmitters(t *testing.T) {
+	var _ Submitter = &ClipboardMode{}
+	var _ Submitter = &TypeMode{}
+	var _ Submitter = &TmuxMode{}
+}
+
+func TestStdoutModeIsNotSubmitter(t *testing.T) {
+	var out OutputMode = &StdoutMode{}
+	if _, ok := out.(Submitter); ok {
+		t.Error("StdoutMode should not implement Submitter")
+	}
+}
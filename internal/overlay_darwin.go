@@ -22,9 +22,35 @@ func OverlayShow(state int) {
 	C.overlayShow(C.int(state))
 }
 
+// OverlayCancel flashes the overlay red, then hides it.
+func OverlayCancel() {
+	if !overlayEnabled {
+		return
+	}
+	C.overlayCancel()
+}
+
+// OverlayStandby shows a grey ring between sessions while the mic is kept
+// open for the pre-roll.
+func OverlayStandby(on bool) {
+	if !overlayEnabled {
+		return
+	}
+	C.overlayStandby(C.bool(on))
+}
+
 func OverlayHide() {
 	if !overlayEnabled {
 		return
 	}
 	C.overlayHide()
 }
+
+// OverlayVisible reports whether the overlay is on screen: it is false while
+// the overlay is off or hidden, e.g. on a locked screen.
+func OverlayVisible() bool {
+	if !overlayEnabled {
+		return false
+	}
+	return bool(C.overlayVisible())
+}
@@ -1,8 +1,13 @@
 #ifndef OVERLAY_DARWIN_H
 #define OVERLAY_DARWIN_H
 
+#include <stdbool.h>
+
 void overlayInit(void);
 void overlayShow(int state);
 void overlayHide(void);
+void overlayCancel(void);
+void overlayStandby(bool on);
+bool overlayVisible(void);
 
 #endif
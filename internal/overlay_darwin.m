@@ -17,6 +17,9 @@ static NSView *ringView = nil;
 static NSImageView *imageView = nil;
 static NSSound *startSound = nil;
 static bool overlayReady = false;
+static int overlayShown = 0; // bumped on every show, so a delayed hide can tell it's stale
+static bool standbyOn = false; // the mic stays open between sessions
+static volatile bool panelOnScreen = false; // read off the main thread by overlayVisible
 
 static NSScreen *currentOverlayScreen(void) {
     NSPoint mouse = [NSEvent mouseLocation];
@@ -35,6 +38,13 @@ static NSScreen *currentOverlayScreen(void) {
     return screens.count > 0 ? screens[0] : nil;
 }
 
+// notePanelVisible records whether the panel is ordered in and not hidden,
+// e.g. by a locked screen or a sleeping display.
+static void notePanelVisible(void) {
+    panelOnScreen = [overlayPanel isVisible] &&
+        ([overlayPanel occlusionState] & NSWindowOcclusionStateVisible) != 0;
+}
+
 static void positionPanel(void) {
     if (!overlayPanel) {
         return;
@@ -104,6 +114,14 @@ static void createPanel(void) {
     // Position: bottom center on the current screen
     positionPanel();
 
+    [[NSNotificationCenter defaultCenter]
+        addObserverForName:NSWindowDidChangeOcclusionStateNotification
+                    object:overlayPanel
+                     queue:nil
+                usingBlock:^(NSNotification *note) {
+        notePanelVisible();
+    }];
+
     // Pre-load start sound from embedded data
     NSData *soundData = [NSData dataWithBytesNoCopy:start_sound_mp3 length:start_sound_mp3_len freeWhenDone:NO];
     startSound = [[NSSound alloc] initWithData:soundData];
@@ -144,6 +162,21 @@ static void playStartSound(void) {
     }
 }
 
+// showIdle shows a grey ring between sessions while the mic is open, or
+// hides the panel otherwise.
+static void showIdle(void) {
+    if (!standbyOn) {
+        [overlayPanel orderOut:nil];
+        notePanelVisible();
+        return;
+    }
+    stopPulse();
+    ringView.layer.borderColor = [[NSColor colorWithSRGBRed:0.6 green:0.6 blue:0.6 alpha:1.0] CGColor];
+    positionPanel();
+    [overlayPanel orderFrontRegardless];
+    notePanelVisible();
+}
+
 void overlayInit(void) {
     dispatch_async(dispatch_get_main_queue(), ^{
         createPanel();
@@ -155,6 +188,7 @@ void overlayShow(int state) {
         if (!overlayReady) {
             createPanel();
         }
+        overlayShown++;
 
         NSColor *color;
         if (state == 0) {
@@ -175,6 +209,7 @@ void overlayShow(int state) {
         positionPanel();
 
         [overlayPanel orderFrontRegardless];
+        notePanelVisible();
     });
 }
 
@@ -182,7 +217,38 @@ void overlayHide(void) {
     dispatch_async(dispatch_get_main_queue(), ^{
         if (overlayReady) {
             stopPulse();
-            [overlayPanel orderOut:nil];
+            showIdle();
         }
     });
 }
+
+void overlayCancel(void) {
+    dispatch_async(dispatch_get_main_queue(), ^{
+        if (!overlayReady) {
+            return;
+        }
+        // Cancelled — red ring, briefly
+        stopPulse();
+        ringView.layer.borderColor = [[NSColor colorWithSRGBRed:0.94 green:0.27 blue:0.27 alpha:1.0] CGColor];
+        int shown = overlayShown;
+        dispatch_after(dispatch_time(DISPATCH_TIME_NOW, (int64_t)(0.6 * NSEC_PER_SEC)), dispatch_get_main_queue(), ^{
+            if (overlayShown == shown) {
+                showIdle();
+            }
+        });
+    });
+}
+
+void overlayStandby(bool on) {
+    dispatch_async(dispatch_get_main_queue(), ^{
+        if (!overlayReady) {
+            createPanel();
+        }
+        standbyOn = on;
+        showIdle();
+    });
+}
+
+bool overlayVisible(void) {
+    return panelOnScreen;
+}
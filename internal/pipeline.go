@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Session describes the dictation session a transcript comes from, for
+// transformers that behave differently per app, profile or project.
+type Session struct {
+	App      AppInfo
+	Profile  string // active profile name, if any
+	Output   string // output mode the text goes to
+	Dir      string // working directory used for the project dictionary
+	Language string
+	Dict     *Dictionary // dictionary with the project and profile layers applied
+	Polish   bool        // the user asked for an LLM rewrite of this session
+}
+
+// Transformer is one stage of the transcript post-processing pipeline.
+type Transformer interface {
+	Transform(text string, s Session) (string, error)
+}
+
+// TransformerFunc adapts a function to the Transformer interface.
+type TransformerFunc func(text string, s Session) (string, error)
+
+func (f TransformerFunc) Transform(text string, s Session) (string, error) {
+	return f(text, s)
+}
+
+// StageConfig configures one pipeline stage, from a [[pipeline]] entry in
+// config.toml. Fields a stage type doesn't use are ignored.
+type StageConfig struct {
+	Type    string            `toml:"type"`
+	Command string            `toml:"command"` // exec: shell command filtering stdin to stdout
+	Timeout string            `toml:"timeout"` // exec: e.g. "5s"
+	Words   []string          `toml:"words"`   // filler: extra words to remove; profanity: words to mask
+	Mask    string            `toml:"mask"`    // profanity: masking character
+	Options map[string]string `toml:"options"` // free-form settings for custom stages
+}
+
+// TransformerFactory builds a stage from its configuration.
+type TransformerFactory func(stage StageConfig, cfg *Config) (Transformer, error)
+
+var transformers = map[string]TransformerFactory{}
+
+// RegisterTransformer makes a stage type available to [[pipeline]] entries.
+// Custom stages compiled into golos register themselves from an init
+// function. It panics if the name is already taken.
+func RegisterTransformer(name string, factory TransformerFactory) {
+	if _, dup := transformers[name]; dup {
+		panic("golos: transformer registered twice: " + name)
+	}
+	transformers[name] = factory
+}
+
+// TransformerTypes lists the registered stage types.
+func TransformerTypes() []string {
+	names := make([]string, 0, len(transformers))
+	for name := range transformers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultPipeline is used when the config has no [[pipeline]] entries:
+// filler removal if enabled, the dictionary, formatting, then polish if
+// an endpoint is configured.
+func defaultPipeline(cfg *Config) []StageConfig {
+	stages := []StageConfig{{Type: "dictionary"}, {Type: "format"}}
+	if cfg.Filler.Enabled {
+		stages = append([]StageConfig{{Type: "filler"}}, stages...)
+	}
+	if cfg.Polish.Endpoint != "" {
+		stages = append(stages, StageConfig{Type: "polish"})
+	}
+	return stages
+}
+
+// Pipeline runs transformers in order.
+type Pipeline struct {
+	names  []string
+	stages []Transformer
+}
+
+// NewPipeline builds the configured pipeline, or the default one if none
+// is configured.
+func NewPipeline(cfg *Config) (*Pipeline, error) {
+	stages := cfg.Pipeline
+	if len(stages) == 0 {
+		stages = defaultPipeline(cfg)
+	}
+	p := &Pipeline{}
+	for i, sc := range stages {
+		factory, ok := transformers[sc.Type]
+		if !ok {
+			return nil, fmt.Errorf("pipeline stage %d: unknown type %q (available: %s)", i+1, sc.Type, strings.Join(TransformerTypes(), ", "))
+		}
+		t, err := factory(sc, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline stage %d (%s): %w", i+1, sc.Type, err)
+		}
+		p.Add(sc.Type, t)
+	}
+	return p, nil
+}
+
+// Add appends a stage to the pipeline.
+func (p *Pipeline) Add(name string, t Transformer) {
+	p.names = append(p.names, name)
+	p.stages = append(p.stages, t)
+}
+
+// Without returns a copy of the pipeline with the stages of the given
+// types left out.
+func (p *Pipeline) Without(types ...string) *Pipeline {
+	out := &Pipeline{}
+	for i, name := range p.names {
+		if !slices.Contains(types, name) {
+			out.Add(name, p.stages[i])
+		}
+	}
+	return out
+}
+
+// Run passes text through each stage. A stage that fails is skipped, so
+// its input carries on to the next stage; the errors are returned
+// together.
+func (p *Pipeline) Run(text string, s Session) (string, error) {
+	var errs []error
+	for i, t := range p.stages {
+		out, err := t.Transform(text, s)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.names[i], err))
+			continue
+		}
+		text = out
+	}
+	return text, errors.Join(errs...)
+}
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewPipelineDefault(t *testing.T) {
+	p, err := NewPipeline(&Config{Format: FormatConfig{Casing: true}})
+	if err != nil {
+		t.Fatalf("NewPipeline: %v", err)
+	}
+	d := &Dictionary{entries: map[string]string{"cube": "kube"}, commands: map[string]string{}}
+	got, err := p.Run("restart cube snake case api server", Session{Dict: d})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got != "restart kube api_server" {
+		t.Errorf("Run = %q, want dictionary then format", got)
+	}
+}
+
+func TestNewPipelineUnknownType(t *testing.T) {
+	_, err := NewPipeline(&Config{Pipeline: []StageConfig{{Type: "dictionary"}, {Type: "rot13"}}})
+	if err == nil || !strings.Contains(err.Error(), `stage 2: unknown type "rot13"`) {
+		t.Errorf("err = %v, want unknown type for stage 2", err)
+	}
+}
+
+func TestNewPipelineStageError(t *testing.T) {
+	_, err := NewPipeline(&Config{Pipeline: []StageConfig{{Type: "exec"}}})
+	if err == nil || !strings.Contains(err.Error(), "command is required") {
+		t.Errorf("err = %v, want missing command", err)
+	}
+}
+
+func TestPipelineSkipsFailingStage(t *testing.T) {
+	p := &Pipeline{}
+	p.Add("upper", TransformerFunc(func(text string, _ Session) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	p.Add("broken", TransformerFunc(func(string, Session) (string, error) {
+		return "", errors.New("boom")
+	}))
+	p.Add("suffix", TransformerFunc(func(text string, s Session) (string, error) {
+		return text + " @" + s.Profile, nil
+	}))
+
+	got, err := p.Run("hi", Session{Profile: "slack"})
+	if got != "HI @slack" {
+		t.Errorf("Run = %q, want failing stage skipped", got)
+	}
+	if err == nil || !strings.Contains(err.Error(), "broken: boom") {
+		t.Errorf("err = %v, want stage error", err)
+	}
+}
+
+func TestPipelineWithout(t *testing.T) {
+	p := &Pipeline{}
+	for _, name := range []string{"a", "exec", "b", "polish"} {
+		p.Add(name, TransformerFunc(func(text string, _ Session) (string, error) {
+			return text + name, nil
+		}))
+	}
+	if got, _ := p.Without("exec", "polish").Run("", Session{}); got != "ab" {
+		t.Errorf("Run = %q, want %q", got, "ab")
+	}
+	if got, _ := p.Run("", Session{}); got != "aexecbpolish" {
+		t.Errorf("original pipeline changed: Run = %q", got)
+	}
+}
+
+func TestRegisterTransformer(t *testing.T) {
+	RegisterTransformer("test-reverse", func(stage StageConfig, _ *Config) (Transformer, error) {
+		sep := stage.Options["sep"]
+		return TransformerFunc(func(text string, _ Session) (string, error) {
+			words := strings.Fields(text)
+			for i, j := 0, len(words)-1; i < j; i, j = i+1, j-1 {
+				words[i], words[j] = words[j], words[i]
+			}
+			return strings.Join(words, sep), nil
+		}), nil
+	})
+	defer delete(transformers, "test-reverse")
+
+	p, err := NewPipeline(&Config{Pipeline: []StageConfig{{Type: "test-reverse", Options: map[string]string{"sep": "-"}}}})
+	if err != nil {
+		t.Fatalf("NewPipeline: %v", err)
+	}
+	if got, _ := p.Run("a b c", Session{}); got != "c-b-a" {
+		t.Errorf("Run = %q, want %q", got, "c-b-a")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice should panic")
+		}
+	}()
+	RegisterTransformer("test-reverse", nil)
+}
+
+func runStage(t *testing.T, stage StageConfig, text string, s Session) string {
+	t.Helper()
+	p, err := NewPipeline(&Config{Pipeline: []StageConfig{stage}})
+	if err != nil {
+		t.Fatalf("NewPipeline: %v", err)
+	}
+	got, err := p.Run(text, s)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	return got
+}
+
+func TestProfanityStage(t *testing.T) {
+	if got := runStage(t, StageConfig{Type: "profanity"}, "well, Shit. that's crap", Session{}); got != "well, S***. that's c***" {
+		t.Errorf("profanity = %q", got)
+	}
+	stage := StageConfig{Type: "profanity", Words: []string{"heck"}, Mask: "#"}
+	if got := runStage(t, stage, "oh heck, shit", Session{}); got != "oh h###, shit" {
+		t.Errorf("custom profanity = %q", got)
+	}
+}
+
+func TestExecStage(t *testing.T) {
+	dir := t.TempDir()
+	stage := StageConfig{Type: "exec", Command: `printf '%s|%s|%s|' "$GOLOS_APP" "$GOLOS_PROFILE" "$(basename "$PWD")"; tr a-z A-Z`}
+	got := runStage(t, stage, "hello\n", Session{App: AppInfo{Name: "Slack"}, Profile: "chat", Dir: dir})
+	want := "Slack|chat|" + dir[strings.LastIndex(dir, "/")+1:] + "|HELLO"
+	if got != want {
+		t.Errorf("exec = %q, want %q", got, want)
+	}
+}
+
+func TestExecStageFailure(t *testing.T) {
+	p, err := NewPipeline(&Config{Pipeline: []StageConfig{{Type: "exec", Command: "echo nope >&2; exit 3"}}})
+	if err != nil {
+		t.Fatalf("NewPipeline: %v", err)
+	}
+	got, err := p.Run("keep me", Session{})
+	if got != "keep me" {
+		t.Errorf("Run = %q, want the input back", got)
+	}
+	if err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("err = %v, want stderr in the error", err)
+	}
+}
+
+func TestExecStageTimeout(t *testing.T) {
+	if _, err := NewPipeline(&Config{Pipeline: []StageConfig{{Type: "exec", Command: "cat", Timeout: "soon"}}}); err == nil {
+		t.Error("invalid timeout should fail")
+	}
+	p, err := NewPipeline(&Config{Pipeline: []StageConfig{{Type: "exec", Command: "sleep 5", Timeout: "50ms"}}})
+	if err != nil {
+		t.Fatalf("NewPipeline: %v", err)
+	}
+	if _, err := p.Run("x", Session{}); err == nil {
+		t.Error("slow command should time out")
+	}
+}
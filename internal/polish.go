@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PolishConfig configures the optional LLM rewrite stage, which sends the
+// transcript to an OpenAI-compatible chat completions endpoint.
+type PolishConfig struct {
+	Endpoint string `toml:"endpoint"` // base URL, e.g. http://localhost:11434/v1
+	Model    string `toml:"model"`
+	APIKey   string `toml:"api_key"`
+	Prompt   string `toml:"prompt"`   // system prompt; a grammar-and-concision prompt if empty
+	Timeout  string `toml:"timeout"`  // default 10s
+	Modifier string `toml:"modifier"` // hold with the hotkey to polish a session, e.g. "shift"
+	Always   bool   `toml:"always"`   // polish every session
+}
+
+const defaultPolishPrompt = "Rewrite the following dictated text. Fix grammar and punctuation, " +
+	"format lists as Markdown lists, and turn rambling speech into a concise, clear request. " +
+	"Keep the meaning and the language. Reply with the rewritten text only."
+
+// Polisher rewrites text with a chat completions endpoint.
+type Polisher struct {
+	url    string
+	model  string
+	apiKey string
+	prompt string
+	client *http.Client
+}
+
+// NewPolisher validates the config and returns a Polisher for it.
+func NewPolisher(cfg PolishConfig) (*Polisher, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+	timeout := 10 * time.Second
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q: %w", cfg.Timeout, err)
+		}
+		timeout = d
+	}
+	url := strings.TrimRight(cfg.Endpoint, "/")
+	if !strings.HasSuffix(url, "/chat/completions") {
+		url += "/chat/completions"
+	}
+	prompt := cfg.Prompt
+	if prompt == "" {
+		prompt = defaultPolishPrompt
+	}
+	return &Polisher{
+		url:    url,
+		model:  cfg.Model,
+		apiKey: cfg.APIKey,
+		prompt: prompt,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model,omitempty"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Polish returns the endpoint's rewrite of text.
+func (p *Polisher) Polish(text string) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: p.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: p.prompt},
+			{Role: "user", Content: text},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var cr chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&cr); err != nil {
+		return "", fmt.Errorf("decoding response: %w", err)
+	}
+	if len(cr.Choices) == 0 || strings.TrimSpace(cr.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("empty response")
+	}
+	return strings.TrimSpace(cr.Choices[0].Message.Content), nil
+}
+
+// newPolishStage rewrites the text with the [polish] endpoint when the
+// session asked for it, or always if configured. On failure the pipeline
+// passes the unpolished text on.
+func newPolishStage(_ StageConfig, cfg *Config) (Transformer, error) {
+	polisher, err := NewPolisher(cfg.Polish)
+	if err != nil {
+		return nil, err
+	}
+	always := cfg.Polish.Always
+	return TransformerFunc(func(text string, s Session) (string, error) {
+		if !s.Polish && !always {
+			return text, nil
+		}
+		return polisher.Polish(text)
+	}), nil
+}
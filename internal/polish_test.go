@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func polishServer(t *testing.T, handler func(req chatRequest) (int, string)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		status, body := handler(req)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPolisher(t *testing.T) {
+	srv := polishServer(t, func(req chatRequest) (int, string) {
+		if req.Model != "llama3" || len(req.Messages) != 2 {
+			t.Errorf("request = %+v", req)
+		}
+		if req.Messages[0].Role != "system" || req.Messages[0].Content != "be brief" {
+			t.Errorf("system message = %+v", req.Messages[0])
+		}
+		reply := strings.ToUpper(req.Messages[1].Content)
+		return http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":" ` + reply + `\n"}}]}`
+	})
+
+	p, err := NewPolisher(PolishConfig{Endpoint: srv.URL + "/v1/", Model: "llama3", APIKey: "secret", Prompt: "be brief"})
+	if err != nil {
+		t.Fatalf("NewPolisher: %v", err)
+	}
+	got, err := p.Polish("so basically fix the login bug")
+	if err != nil {
+		t.Fatalf("Polish: %v", err)
+	}
+	if got != "SO BASICALLY FIX THE LOGIN BUG" {
+		t.Errorf("Polish = %q", got)
+	}
+}
+
+func TestPolisherErrors(t *testing.T) {
+	if _, err := NewPolisher(PolishConfig{}); err == nil {
+		t.Error("missing endpoint should fail")
+	}
+	if _, err := NewPolisher(PolishConfig{Endpoint: "http://x", Timeout: "later"}); err == nil {
+		t.Error("invalid timeout should fail")
+	}
+
+	srv := polishServer(t, func(chatRequest) (int, string) {
+		return http.StatusInternalServerError, "model not loaded"
+	})
+	p, _ := NewPolisher(PolishConfig{Endpoint: srv.URL + "/v1", APIKey: "secret"})
+	if _, err := p.Polish("hi"); err == nil || !strings.Contains(err.Error(), "model not loaded") {
+		t.Errorf("err = %v, want server message", err)
+	}
+
+	empty := polishServer(t, func(chatRequest) (int, string) {
+		return http.StatusOK, `{"choices":[]}`
+	})
+	p, _ = NewPolisher(PolishConfig{Endpoint: empty.URL + "/v1/chat/completions", APIKey: "secret"})
+	if _, err := p.Polish("hi"); err == nil {
+		t.Error("empty response should fail")
+	}
+}
+
+func TestPolishStage(t *testing.T) {
+	srv := polishServer(t, func(req chatRequest) (int, string) {
+		return http.StatusOK, `{"choices":[{"message":{"content":"Polished."}}]}`
+	})
+	cfg := &Config{Polish: PolishConfig{Endpoint: srv.URL + "/v1", APIKey: "secret"}}
+	p, err := NewPipeline(cfg)
+	if err != nil {
+		t.Fatalf("NewPipeline: %v", err)
+	}
+
+	// Only sessions that asked for it are polished.
+	if got, _ := p.Run("raw text", Session{}); got != "raw text" {
+		t.Errorf("unrequested: got %q", got)
+	}
+	if got, _ := p.Run("raw text", Session{Polish: true}); got != "Polished." {
+		t.Errorf("requested: got %q", got)
+	}
+
+	cfg.Polish.Always = true
+	p, _ = NewPipeline(cfg)
+	if got, _ := p.Run("raw text", Session{}); got != "Polished." {
+		t.Errorf("always: got %q", got)
+	}
+}
+
+func TestPolishStageFallsBackOnTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	cfg := &Config{Polish: PolishConfig{Endpoint: srv.URL, Timeout: "20ms", Always: true}}
+	p, err := NewPipeline(cfg)
+	if err != nil {
+		t.Fatalf("NewPipeline: %v", err)
+	}
+	got, err := p.Run("raw text", Session{})
+	if got != "raw text" {
+		t.Errorf("Run = %q, want the unpolished text", got)
+	}
+	if err == nil || !strings.Contains(err.Error(), "polish:") {
+		t.Errorf("err = %v, want a polish error", err)
+	}
+}
@@ -0,0 +1,233 @@
+package internal
+
+import (
+	"sync"
+	"time"
+)
+
+// AudioSource delivers 20ms PCM16 frames until stopped. Stop closes the
+// frames channel. Gaps reports frames dropped because the consumer fell
+// behind.
+type AudioSource interface {
+	Frames() <-chan []int16
+	Stop()
+	Gaps() []Gap
+}
+
+// Ring keeps the most recent frames, dropping the oldest.
+type Ring struct {
+	frames [][]int16
+	next   int
+	full   bool
+}
+
+// NewRing returns a ring holding up to size frames.
+func NewRing(size int) *Ring {
+	if size < 1 {
+		size = 1
+	}
+	return &Ring{frames: make([][]int16, size)}
+}
+
+// Push adds a frame, overwriting the oldest when the ring is full.
+func (r *Ring) Push(frame []int16) {
+	r.frames[r.next] = frame
+	r.next = (r.next + 1) % len(r.frames)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Drain returns the frames oldest first and empties the ring.
+func (r *Ring) Drain() [][]int16 {
+	var out [][]int16
+	if r.full {
+		out = append(out, r.frames[r.next:]...)
+	}
+	out = append(out, r.frames[:r.next]...)
+	clear(r.frames)
+	r.next, r.full = 0, false
+	return out
+}
+
+// Standby keeps the mic open between sessions so speech that starts as
+// the hotkey goes down isn't clipped while the device warms up. Only the
+// last pre-roll of audio is kept, in memory; it is never written out and
+// is thrown away once a session has taken it or the mic is paused.
+type Standby struct {
+	open func() (AudioSource, error)
+
+	mu      sync.Mutex
+	stop    func() // closes the mic; nil while paused
+	gen     int    // bumped whenever the mic closes, so a stale pump stops
+	ring    *Ring
+	session *standbySession // nil between sessions
+	closed  bool
+}
+
+// NewStandby opens the mic at rate and starts keeping preRoll of audio.
+func NewStandby(preRoll time.Duration, rate int) (*Standby, error) {
+	return newStandby(func() (AudioSource, error) {
+		c, err := NewCapture(128, rate)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.Start(); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}, preRoll)
+}
+
+func newStandby(open func() (AudioSource, error), preRoll time.Duration) (*Standby, error) {
+	s := &Standby{
+		open: open,
+		ring: NewRing(int(preRoll / (FrameDurMs * time.Millisecond))),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.resume(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// resume opens the mic unless it is open or the standby is closed.
+// Caller must hold s.mu.
+func (s *Standby) resume() error {
+	if s.stop != nil || s.closed {
+		return nil
+	}
+	src, err := s.open()
+	if err != nil {
+		return err
+	}
+	s.stop = src.Stop
+	go s.pump(src.Frames(), s.gen)
+	return nil
+}
+
+func (s *Standby) pump(frames <-chan []int16, gen int) {
+	for frame := range frames {
+		s.mu.Lock()
+		switch ss := s.session; {
+		case gen != s.gen:
+			// The mic was paused or closed; drop what it still had.
+		case ss != nil:
+			select {
+			case ss.frames <- frame:
+				ss.gaps.sent()
+			default:
+				// Drop frame if consumer is too slow, as Capture does
+				ss.gaps.dropped()
+			}
+		default:
+			s.ring.Push(frame)
+		}
+		s.mu.Unlock()
+	}
+	// The mic stopped on its own: end the session, and let the next
+	// Begin open it again.
+	s.mu.Lock()
+	if gen == s.gen {
+		if s.session != nil {
+			close(s.session.frames)
+			s.session = nil
+		}
+		s.halt()
+	}
+	s.mu.Unlock()
+}
+
+// Begin starts a session, opening the mic again if it was paused. Its
+// frames start with the pre-roll, followed by live audio until the
+// session is stopped.
+func (s *Standby) Begin(bufferSize int) (AudioSource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.resume(); err != nil {
+		return nil, err
+	}
+	pre := s.ring.Drain()
+	ss := &standbySession{s: s, frames: make(chan []int16, bufferSize+len(pre))}
+	for _, frame := range pre {
+		ss.frames <- frame
+		ss.gaps.sent()
+	}
+	if s.closed {
+		close(ss.frames)
+	} else {
+		s.session = ss
+	}
+	return ss, nil
+}
+
+// Pause closes the mic and forgets the pre-roll, unless a session is
+// using it. It reports whether the mic is closed. Resume or Begin opens
+// it again.
+func (s *Standby) Pause() bool {
+	s.mu.Lock()
+	if s.session != nil {
+		s.mu.Unlock()
+		return false
+	}
+	stop := s.halt()
+	s.ring.Drain()
+	s.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+	return true
+}
+
+// Resume opens the mic again after Pause.
+func (s *Standby) Resume() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resume()
+}
+
+// Close stops the mic for good and ends the current session, if any.
+func (s *Standby) Close() {
+	s.mu.Lock()
+	s.closed = true
+	if s.session != nil {
+		close(s.session.frames)
+		s.session = nil
+	}
+	stop := s.halt()
+	s.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+}
+
+// halt detaches the open mic from the standby and returns the function
+// that closes it, to be called without s.mu held. Caller must hold s.mu.
+func (s *Standby) halt() func() {
+	stop := s.stop
+	s.stop = nil
+	s.gen++
+	return stop
+}
+
+// standbySession is a session's view of the standby mic. Stopping it
+// ends the session but leaves the mic open.
+type standbySession struct {
+	s      *Standby
+	frames chan []int16
+	gaps   gapLog
+}
+
+func (ss *standbySession) Frames() <-chan []int16 { return ss.frames }
+func (ss *standbySession) Gaps() []Gap            { return ss.gaps.list() }
+
+func (ss *standbySession) Stop() {
+	s := ss.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.session == ss {
+		close(ss.frames)
+		s.session = nil
+	}
+}
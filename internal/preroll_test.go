@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func frameOf(v int16) []int16 {
+	return []int16{v}
+}
+
+func firstSamples(frames [][]int16) []int16 {
+	out := make([]int16, len(frames))
+	for i, f := range frames {
+		out[i] = f[0]
+	}
+	return out
+}
+
+func TestRing(t *testing.T) {
+	r := NewRing(3)
+	if got := r.Drain(); len(got) != 0 {
+		t.Errorf("empty ring drained %d frames", len(got))
+	}
+	r.Push(frameOf(1))
+	r.Push(frameOf(2))
+	if got := firstSamples(r.Drain()); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("Drain = %v, want [1 2]", got)
+	}
+	for v := int16(1); v <= 5; v++ {
+		r.Push(frameOf(v))
+	}
+	if got := firstSamples(r.Drain()); len(got) != 3 || got[0] != 3 || got[2] != 5 {
+		t.Errorf("Drain = %v, want [3 4 5]", got)
+	}
+	if got := r.Drain(); len(got) != 0 {
+		t.Errorf("Drain after Drain returned %d frames", len(got))
+	}
+}
+
+// fakeMic is an AudioSource fed by the test.
+type fakeMic struct {
+	frames chan []int16
+}
+
+func (m *fakeMic) Frames() <-chan []int16 { return m.frames }
+func (m *fakeMic) Stop()                  { close(m.frames) }
+func (m *fakeMic) Gaps() []Gap            { return nil }
+
+// newTestStandby returns a standby on mic.
+func newTestStandby(t *testing.T, mic *fakeMic, preRoll time.Duration) *Standby {
+	t.Helper()
+	s, err := newStandby(func() (AudioSource, error) { return mic, nil }, preRoll)
+	if err != nil {
+		t.Fatalf("newStandby: %v", err)
+	}
+	return s
+}
+
+func begin(t *testing.T, s *Standby, bufferSize int) AudioSource {
+	t.Helper()
+	session, err := s.Begin(bufferSize)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	return session
+}
+
+// settle waits for the standby pump to take everything sent so far.
+func settle(m *fakeMic) {
+	for len(m.frames) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond)
+}
+
+func TestStandbyPrependsPreRoll(t *testing.T) {
+	mic := &fakeMic{frames: make(chan []int16, 16)}
+	s := newTestStandby(t, mic, 40*time.Millisecond) // two frames
+	for v := int16(1); v <= 4; v++ {
+		mic.frames <- frameOf(v)
+	}
+	settle(mic)
+
+	session := begin(t, s, 8)
+	mic.frames <- frameOf(5)
+	settle(mic)
+	session.Stop()
+
+	var got []int16
+	for f := range session.Frames() {
+		got = append(got, f[0])
+	}
+	if len(got) != 3 || got[0] != 3 || got[1] != 4 || got[2] != 5 {
+		t.Errorf("session frames = %v, want [3 4 5]", got)
+	}
+
+	// The pre-roll used by one session is not handed to the next.
+	next := begin(t, s, 8)
+	next.Stop()
+	if n := len(next.Frames()); n != 0 {
+		t.Errorf("next session got %d stale frames", n)
+	}
+	s.Close()
+}
+
+func TestStandbyCloseEndsSession(t *testing.T) {
+	mic := &fakeMic{frames: make(chan []int16, 4)}
+	s := newTestStandby(t, mic, 0)
+	session := begin(t, s, 4)
+	s.Close()
+	select {
+	case _, ok := <-session.Frames():
+		for ok {
+			_, ok = <-session.Frames()
+		}
+	case <-time.After(time.Second):
+		t.Fatal("session frames not closed with the mic")
+	}
+	session.Stop() // no double close
+}
+
+func TestStandbyPauseClosesMic(t *testing.T) {
+	mics := []*fakeMic{{frames: make(chan []int16, 4)}, {frames: make(chan []int16, 4)}}
+	opened := 0
+	s, err := newStandby(func() (AudioSource, error) {
+		opened++
+		return mics[opened-1], nil
+	}, 40*time.Millisecond)
+	if err != nil {
+		t.Fatalf("newStandby: %v", err)
+	}
+	mics[0].frames <- frameOf(1)
+	settle(mics[0])
+
+	session := begin(t, s, 4)
+	if s.Pause() {
+		t.Error("paused the mic under a running session")
+	}
+	session.Stop()
+	if !s.Pause() {
+		t.Fatal("Pause refused without a session")
+	}
+	if _, ok := <-mics[0].frames; ok {
+		t.Error("mic left open after Pause")
+	}
+
+	// Begin opens the mic again, without the audio from before the pause.
+	next := begin(t, s, 4)
+	if opened != 2 {
+		t.Errorf("opened the mic %d times, want 2", opened)
+	}
+	mics[1].frames <- frameOf(2)
+	settle(mics[1])
+	next.Stop()
+	var got []int16
+	for f := range next.Frames() {
+		got = append(got, f[0])
+	}
+	if len(got) != 1 || got[0] != 2 {
+		t.Errorf("frames after resuming = %v, want [2]", got)
+	}
+	s.Close()
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// projectDictionaryPath is where a repository keeps its shared vocabulary,
+// relative to any directory from the working directory up to the root.
+var projectDictionaryPath = filepath.Join(".golos", "dictionary.toml")
+
+// WorkingDir returns the directory to search for a project dictionary: the
+// configured project_dir, else the cwd of the tmux pane golos types into,
+// else the process working directory.
+func (c *Config) WorkingDir() string {
+	if c.ProjectDir != "" {
+		return expandHome(c.ProjectDir)
+	}
+	if c.TmuxTarget != "" || c.OutputMode == "tmux" || os.Getenv("TMUX") != "" {
+		if dir, err := TmuxPaneDir(c.TmuxTarget); err == nil && dir != "" {
+			return dir
+		}
+	}
+	dir, _ := os.Getwd()
+	return dir
+}
+
+// FindProjectDictionary walks up from dir looking for .golos/dictionary.toml
+// and returns its path, or "" if there is none.
+func FindProjectDictionary(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, projectDictionaryPath)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// WithProject returns a copy of d for a session in dir: {branch} is read
+// from dir's repository, and the plain-text words of the project dictionary
+// found from dir are layered on top. Its [commands] are ignored. The path
+// is empty when there is no project dictionary or it cannot be read.
+func (d *Dictionary) WithProject(dir string) (*Dictionary, string) {
+	d = d.clone()
+	d.dir = dir
+
+	path := FindProjectDictionary(dir)
+	if path == "" {
+		return d, ""
+	}
+	f, err := readDictionaryFile(path)
+	if err != nil {
+		return d, ""
+	}
+	return d.WithLayer(Layer{Source: "project", Words: sharedWords(f.Words)}), path
+}
+
+func expandHome(path string) string {
+	if len(path) < 2 || path[:2] != "~/" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
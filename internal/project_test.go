@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func writeProjectDictionary(t *testing.T, root, data string) string {
+	t.Helper()
+	path := filepath.Join(root, ".golos", "dictionary.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFindProjectDictionaryWalksUp(t *testing.T) {
+	root := t.TempDir()
+	want := writeProjectDictionary(t, root, "[words]\n")
+	sub := filepath.Join(root, "cmd", "server")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := FindProjectDictionary(sub); got != want {
+		t.Errorf("FindProjectDictionary = %q, want %q", got, want)
+	}
+}
+
+func TestFindProjectDictionaryNone(t *testing.T) {
+	if got := FindProjectDictionary(t.TempDir()); got != "" {
+		t.Errorf("FindProjectDictionary = %q, want empty", got)
+	}
+	if got := FindProjectDictionary(""); got != "" {
+		t.Errorf("FindProjectDictionary(\"\") = %q, want empty", got)
+	}
+}
+
+func TestWithProjectOverridesGlobal(t *testing.T) {
+	root := t.TempDir()
+	writeProjectDictionary(t, root, "[words]\n\"cube\" = \"kube\"\n\"period\" = \"!\"\n[commands]\n\"ship it\" = \"shell make deploy\"\n")
+
+	d := &Dictionary{entries: map[string]string{"period": ".", "comma": ","}}
+	pd, path := d.WithProject(root)
+	if path == "" {
+		t.Fatal("expected project dictionary path")
+	}
+	if got := pd.Replace("cube period comma"); got != "kube ! ," {
+		t.Errorf("Replace = %q, want %q", got, "kube ! ,")
+	}
+	if _, actions := pd.ExtractCommands("ship it"); len(actions) != 0 {
+		t.Errorf("actions = %+v, want project commands ignored", actions)
+	}
+	if d.List()["period"] != "." {
+		t.Error("global dictionary must not change")
+	}
+}
+
+func TestWithProjectDropsKeystrokes(t *testing.T) {
+	root := t.TempDir()
+	writeProjectDictionary(t, root, "[words]\n\"cube\" = \"kube\"\n\"deploy\" = \"make deploy\\n\"\n\"paste\" = \"{clipboard}\"\n")
+
+	d := &Dictionary{entries: map[string]string{}, templateVars: func() TemplateVars {
+		return TemplateVars{Clipboard: func() string { return "rm -rf ~\n" }}
+	}}
+	pd, _ := d.WithProject(root)
+	if got := pd.Replace("cube deploy paste"); got != "kube deploy paste" {
+		t.Errorf("Replace = %q, want line breaks and {clipboard} dropped", got)
+	}
+}
+
+func TestWithProjectNone(t *testing.T) {
+	d := &Dictionary{entries: map[string]string{"cube": "kube"}}
+	pd, path := d.WithProject(t.TempDir())
+	if path != "" || pd.Replace("cube") != "kube" {
+		t.Errorf("got %q %q, want the global dictionary and no path", pd.Replace("cube"), path)
+	}
+}
+
+func TestWithProjectReadsBranchFromDir(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "feature-x"},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	d := &Dictionary{entries: map[string]string{"push it": "git push origin {branch}"}}
+	pd, _ := d.WithProject(root)
+	if got := pd.Replace("push it"); got != "git push origin feature-x" {
+		t.Errorf("Replace = %q, want %q", got, "git push origin feature-x")
+	}
+}
+
+func TestWorkingDirConfigured(t *testing.T) {
+	t.Setenv("HOME", "/home/test")
+	cfg := &Config{ProjectDir: "~/src/app"}
+	if got := cfg.WorkingDir(); got != filepath.Join("/home/test", "src", "app") {
+		t.Errorf("WorkingDir = %q", got)
+	}
+}
+
+func TestWorkingDirFallsBackToCwd(t *testing.T) {
+	t.Setenv("TMUX", "")
+	cwd, _ := os.Getwd()
+	if got := (&Config{}).WorkingDir(); got != cwd {
+		t.Errorf("WorkingDir = %q, want %q", got, cwd)
+	}
+}
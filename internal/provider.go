@@ -2,9 +2,10 @@ package internal
 
 // TranscriptResult holds a transcript from the STT provider.
 type TranscriptResult struct {
-	Text    string
-	IsFinal bool
+	Text        string
+	IsFinal     bool
 	SpeechFinal bool
+	Language    string // spoken language, detected when the language is auto
 }
 
 // Provider is the interface for speech-to-text backends.
@@ -0,0 +1,299 @@
+package internal
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SessionLog is the JSON sidecar of a recorded session: every result the
+// provider sent, with when it arrived.
+type SessionLog struct {
+	Time       time.Time        `json:"time"`
+	SampleRate int              `json:"sample_rate"`
+	Language   string           `json:"language,omitempty"`
+	Results    []RecordedResult `json:"results"`
+}
+
+// RecordedResult is a TranscriptResult and its arrival time, in
+// milliseconds from the start of the session.
+type RecordedResult struct {
+	AtMs        int64  `json:"at_ms"`
+	Text        string `json:"text"`
+	IsFinal     bool   `json:"is_final,omitempty"`
+	SpeechFinal bool   `json:"speech_final,omitempty"`
+	Language    string `json:"language,omitempty"`
+}
+
+// Transcript joins the final results, as the processor does.
+func (l SessionLog) Transcript() string {
+	var parts []string
+	for _, r := range l.Results {
+		if r.IsFinal && r.Text != "" {
+			parts = append(parts, r.Text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Recording writes a session's audio to a WAV file in
+// ~/.config/golos/recordings and its results to a .json file next to it.
+// Its methods may be called from different goroutines.
+type Recording struct {
+	mu      sync.Mutex
+	f       *os.File
+	start   time.Time
+	samples int
+	log     SessionLog
+	closed  bool
+}
+
+func recordingsDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "golos", "recordings")
+}
+
+// NewRecording starts a WAV file named after the current time.
+func NewRecording(rate int, language string) (*Recording, error) {
+	dir := recordingsDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	name := now.Format("20060102-150405")
+	var f *os.File
+	for i := 1; ; i++ {
+		path := filepath.Join(dir, name+".wav")
+		if i > 1 {
+			path = filepath.Join(dir, fmt.Sprintf("%s-%d.wav", name, i))
+		}
+		var err error
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+	}
+	r := &Recording{f: f, start: now, log: SessionLog{Time: now, SampleRate: rate, Language: language}}
+	// The sizes are filled in by Close.
+	if err := writeWAVHeader(f, rate, 0); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Path returns the WAV file's path.
+func (r *Recording) Path() string {
+	return r.f.Name()
+}
+
+// Write appends a frame of audio.
+func (r *Recording) Write(frame []int16) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	if err := binary.Write(r.f, binary.LittleEndian, frame); err != nil {
+		return err
+	}
+	r.samples += len(frame)
+	return nil
+}
+
+// AddResult notes a result from the provider.
+func (r *Recording) AddResult(res TranscriptResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.log.Results = append(r.log.Results, RecordedResult{
+		AtMs:        time.Since(r.start).Milliseconds(),
+		Text:        res.Text,
+		IsFinal:     res.IsFinal,
+		SpeechFinal: res.SpeechFinal,
+		Language:    res.Language,
+	})
+}
+
+// Close finishes the WAV header and writes the sidecar.
+func (r *Recording) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if _, err := r.f.Seek(0, io.SeekStart); err != nil {
+		_ = r.f.Close()
+		return err
+	}
+	if err := writeWAVHeader(r.f, r.log.SampleRate, r.samples*2); err != nil {
+		_ = r.f.Close()
+		return err
+	}
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r.log, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(sidecarPath(r.f.Name()), append(data, '\n'), 0600)
+}
+
+// Discard closes the recording and removes it.
+func (r *Recording) Discard() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	_ = r.f.Close()
+	_ = os.Remove(r.f.Name())
+}
+
+func sidecarPath(wav string) string {
+	return strings.TrimSuffix(wav, ".wav") + ".json"
+}
+
+// writeWAVHeader writes a 44-byte header for mono PCM16 audio.
+func writeWAVHeader(w io.Writer, rate, dataBytes int) error {
+	h := struct {
+		Riff          [4]byte
+		Size          uint32
+		Wave, Fmt     [4]byte
+		FmtSize       uint32
+		Format, Chans uint16
+		Rate, Bytes   uint32
+		Align, Bits   uint16
+		Data          [4]byte
+		DataSize      uint32
+	}{
+		Riff: [4]byte{'R', 'I', 'F', 'F'}, Size: uint32(36 + dataBytes),
+		Wave: [4]byte{'W', 'A', 'V', 'E'}, Fmt: [4]byte{'f', 'm', 't', ' '},
+		FmtSize: 16, Format: 1, Chans: Channels,
+		Rate: uint32(rate), Bytes: uint32(rate * 2), Align: 2, Bits: 16,
+		Data: [4]byte{'d', 'a', 't', 'a'}, DataSize: uint32(dataBytes),
+	}
+	return binary.Write(w, binary.LittleEndian, h)
+}
+
+// ReadWAV reads a mono PCM16 WAV file and returns its samples and rate.
+func ReadWAV(path string) ([]int16, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, 0, fmt.Errorf("%s: not a WAV file", path)
+	}
+	rate := 0
+	for rest := data[12:]; len(rest) >= 8; {
+		id, size := string(rest[:4]), int(binary.LittleEndian.Uint32(rest[4:8]))
+		body := rest[8:]
+		if size > len(body) {
+			size = len(body)
+		}
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, 0, fmt.Errorf("%s: bad fmt chunk", path)
+			}
+			format, chans := binary.LittleEndian.Uint16(body), binary.LittleEndian.Uint16(body[2:])
+			bits := binary.LittleEndian.Uint16(body[14:])
+			if format != 1 || chans != 1 || bits != 16 {
+				return nil, 0, fmt.Errorf("%s: need mono 16-bit PCM", path)
+			}
+			rate = int(binary.LittleEndian.Uint32(body[4:]))
+		case "data":
+			if rate == 0 {
+				return nil, 0, fmt.Errorf("%s: data before fmt chunk", path)
+			}
+			samples := make([]int16, size/2)
+			for i := range samples {
+				samples[i] = int16(binary.LittleEndian.Uint16(body[i*2:]))
+			}
+			return samples, rate, nil
+		}
+		if size+size%2 > len(body) {
+			break // truncated: the chunk's pad byte is missing
+		}
+		rest = body[size+size%2:]
+	}
+	return nil, 0, fmt.Errorf("%s: no audio data", path)
+}
+
+// ErrNoRecordings is returned when no session has been recorded yet.
+var ErrNoRecordings = errors.New("no recorded sessions yet — set record_audio = true")
+
+// FindRecording resolves a session given as a path, a name such as
+// "20260102-150405", or "last" for the newest, to its WAV file.
+func FindRecording(session string) (string, error) {
+	if session == "" || session == "last" {
+		names, err := ListRecordings()
+		if err != nil {
+			return "", err
+		}
+		if len(names) == 0 {
+			return "", ErrNoRecordings
+		}
+		session = names[len(names)-1]
+	}
+	path := session
+	if _, err := os.Stat(path); err != nil {
+		path = filepath.Join(recordingsDir(), strings.TrimSuffix(session, ".wav")+".wav")
+	}
+	path = strings.TrimSuffix(path, ".json")
+	if !strings.HasSuffix(path, ".wav") {
+		path += ".wav"
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("no recorded session %q", session)
+	}
+	return path, nil
+}
+
+// ListRecordings returns the names of the recorded sessions, oldest
+// first.
+func ListRecordings() ([]string, error) {
+	entries, err := os.ReadDir(recordingsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".wav"); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoadSessionLog reads the sidecar of a recorded WAV file.
+func LoadSessionLog(wav string) (SessionLog, error) {
+	var l SessionLog
+	data, err := os.ReadFile(sidecarPath(wav))
+	if err != nil {
+		return l, err
+	}
+	err = json.Unmarshal(data, &l)
+	return l, err
+}
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordingRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	rec, err := NewRecording(16000, "en-US")
+	if err != nil {
+		t.Fatalf("NewRecording: %v", err)
+	}
+	frame := make([]int16, FrameSamples)
+	for i := range frame {
+		frame[i] = int16(i - 100)
+	}
+	_ = rec.Write(frame)
+	_ = rec.Write(frame)
+	rec.AddResult(TranscriptResult{Text: "hello"})
+	rec.AddResult(TranscriptResult{Text: "hello world", IsFinal: true})
+	rec.AddResult(TranscriptResult{Text: "again", IsFinal: true, SpeechFinal: true})
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	path, err := FindRecording("last")
+	if err != nil || path != rec.Path() {
+		t.Fatalf("FindRecording(last) = %q, %v, want %q", path, err, rec.Path())
+	}
+	samples, rate, err := ReadWAV(path)
+	if err != nil {
+		t.Fatalf("ReadWAV: %v", err)
+	}
+	if rate != 16000 || len(samples) != 2*FrameSamples || samples[FrameSamples+5] != frame[5] {
+		t.Errorf("ReadWAV = %d samples at %d Hz, want %d at 16000", len(samples), rate, 2*FrameSamples)
+	}
+
+	log, err := LoadSessionLog(path)
+	if err != nil {
+		t.Fatalf("LoadSessionLog: %v", err)
+	}
+	if len(log.Results) != 3 || log.Language != "en-US" || log.SampleRate != 16000 {
+		t.Errorf("log = %+v", log)
+	}
+	if got := log.Transcript(); got != "hello world again" {
+		t.Errorf("Transcript() = %q, want %q", got, "hello world again")
+	}
+}
+
+func TestFindRecordingByName(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if _, err := FindRecording("last"); err != ErrNoRecordings {
+		t.Errorf("FindRecording with none = %v, want ErrNoRecordings", err)
+	}
+	a, _ := NewRecording(16000, "")
+	b, _ := NewRecording(16000, "")
+	_ = a.Close()
+	_ = b.Close()
+	if a.Path() == b.Path() {
+		t.Fatal("two recordings in the same second share a file")
+	}
+
+	name := filepath.Base(a.Path())
+	for _, session := range []string{name, name[:len(name)-4], a.Path(), a.Path()[:len(a.Path())-4] + ".json"} {
+		if got, err := FindRecording(session); err != nil || got != a.Path() {
+			t.Errorf("FindRecording(%q) = %q, %v, want %q", session, got, err, a.Path())
+		}
+	}
+	if _, err := FindRecording("19990101-000000"); err == nil {
+		t.Error("expected error for a missing session")
+	}
+	names, _ := ListRecordings()
+	if len(names) != 2 {
+		t.Errorf("ListRecordings() = %v, want 2 names", names)
+	}
+}
+
+func TestRecordingDiscard(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	rec, _ := NewRecording(16000, "")
+	_ = rec.Write(make([]int16, FrameSamples))
+	rec.Discard()
+	if _, err := os.Stat(rec.Path()); !os.IsNotExist(err) {
+		t.Error("discarded recording still exists")
+	}
+	if err := rec.Close(); err != nil {
+		t.Errorf("Close after Discard: %v", err)
+	}
+}
+
+func TestReadWAVRejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.wav")
+	_ = os.WriteFile(path, []byte("not a wav file at all"), 0600)
+	if _, _, err := ReadWAV(path); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestReadWAVTruncatedChunk(t *testing.T) {
+	// A 3-byte chunk whose pad byte was cut off ends the file.
+	data := append([]byte("RIFF\x00\x00\x00\x00WAVE"), "junk\x03\x00\x00\x00abc"...)
+	path := filepath.Join(t.TempDir(), "x.wav")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ReadWAV(path); err == nil {
+		t.Error("expected error for a file without audio")
+	}
+}
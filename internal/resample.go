@@ -0,0 +1,75 @@
+package internal
+
+import "math"
+
+// resampleTaps is how many input samples each side of an output sample
+// the filter looks at, at full bandwidth. Downsampling widens it in
+// proportion so the low-pass stays as sharp.
+const resampleTaps = 8
+
+// Resampler converts a stream of PCM16 samples from one rate to another
+// with a windowed-sinc filter, which also low-passes when downsampling
+// so higher frequencies don't alias into speech. Input may arrive in
+// chunks of any size; output is produced as soon as enough input follows
+// it.
+type Resampler struct {
+	step   float64 // input samples per output sample
+	cutoff float64 // filter cutoff as a fraction of the input Nyquist rate
+	half   int     // taps on each side
+	buf    []float64
+	pos    float64 // position of the next output sample in buf
+}
+
+// NewResampler returns a resampler from inRate to outRate.
+func NewResampler(inRate, outRate int) *Resampler {
+	r := &Resampler{
+		step:   float64(inRate) / float64(outRate),
+		cutoff: min(1, float64(outRate)/float64(inRate)),
+	}
+	r.half = int(math.Ceil(resampleTaps / r.cutoff))
+	// Start with silence before the first sample so it gets a full filter.
+	r.buf = make([]float64, r.half)
+	r.pos = float64(r.half)
+	return r
+}
+
+// Process adds samples and returns the output they complete.
+func (r *Resampler) Process(samples []int16) []int16 {
+	for _, s := range samples {
+		r.buf = append(r.buf, float64(s))
+	}
+
+	var out []int16
+	for r.pos+float64(r.half) < float64(len(r.buf)) {
+		out = append(out, r.sample(r.pos))
+		r.pos += r.step
+	}
+
+	// Drop input no later output needs.
+	if drop := int(r.pos) - r.half; drop > 0 {
+		r.buf = append(r.buf[:0], r.buf[drop:]...)
+		r.pos -= float64(drop)
+	}
+	return out
+}
+
+func (r *Resampler) sample(t float64) int16 {
+	center := int(t)
+	var sum float64
+	for k := center - r.half + 1; k <= center+r.half; k++ {
+		d := t - float64(k)
+		if math.Abs(d) >= float64(r.half) {
+			continue
+		}
+		w := 0.5 * (1 + math.Cos(math.Pi*d/float64(r.half))) // Hann window
+		sum += r.buf[k] * r.cutoff * sinc(r.cutoff*d) * w
+	}
+	return int16(max(math.MinInt16, min(math.MaxInt16, math.Round(sum))))
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
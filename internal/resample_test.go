@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"math"
+	"testing"
+)
+
+func sine(rate, freq, n int) []int16 {
+	out := make([]int16, n)
+	for i := range out {
+		out[i] = int16(10000 * math.Sin(2*math.Pi*float64(freq)*float64(i)/float64(rate)))
+	}
+	return out
+}
+
+func rms(samples []int16) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+// resampleChunks feeds in through a resampler in chunks of size.
+func resampleChunks(in []int16, inRate, outRate, size int) []int16 {
+	r := NewResampler(inRate, outRate)
+	var out []int16
+	for i := 0; i < len(in); i += size {
+		out = append(out, r.Process(in[i:min(i+size, len(in))])...)
+	}
+	return out
+}
+
+func TestResamplerLength(t *testing.T) {
+	tests := []struct{ in, out int }{{48000, 16000}, {44100, 16000}, {16000, 48000}, {16000, 16000}}
+	for _, tt := range tests {
+		got := len(resampleChunks(sine(tt.in, 440, tt.in), tt.in, tt.out, FrameSize(tt.in)))
+		// Output lags the input by half the filter.
+		if got > tt.out || got < tt.out-tt.out/50 {
+			t.Errorf("%d→%d: %d samples from one second, want about %d", tt.in, tt.out, got, tt.out)
+		}
+	}
+}
+
+func TestResamplerChunkingDoesNotMatter(t *testing.T) {
+	in := sine(44100, 440, 44100/2)
+	whole := resampleChunks(in, 44100, 16000, len(in))
+	chunked := resampleChunks(in, 44100, 16000, 441)
+	if len(whole) != len(chunked) {
+		t.Fatalf("lengths differ: %d vs %d", len(whole), len(chunked))
+	}
+	for i := range whole {
+		if whole[i] != chunked[i] {
+			t.Fatalf("sample %d differs: %d vs %d", i, whole[i], chunked[i])
+		}
+	}
+}
+
+func TestResamplerKeepsSpeechBand(t *testing.T) {
+	out := resampleChunks(sine(48000, 1000, 48000), 48000, 16000, 960)
+	want := rms(sine(16000, 1000, 16000))
+	if got := rms(out[1000:]); math.Abs(got-want) > want*0.05 {
+		t.Errorf("1 kHz RMS = %.0f after resampling, want about %.0f", got, want)
+	}
+}
+
+func TestResamplerFiltersAliases(t *testing.T) {
+	// 12 kHz is above the 8 kHz Nyquist rate at 16 kHz and would fold
+	// back to 4 kHz without the low-pass.
+	out := resampleChunks(sine(48000, 12000, 48000), 48000, 16000, 960)
+	if got := rms(out[1000:]); got > 100 {
+		t.Errorf("12 kHz RMS = %.0f after resampling, want it filtered out", got)
+	}
+}
+
+func TestCheckSampleRate(t *testing.T) {
+	for _, rate := range []int{8000, 16000, 32000, 48000} {
+		if err := CheckSampleRate(rate); err != nil {
+			t.Errorf("CheckSampleRate(%d): %v", rate, err)
+		}
+	}
+	for _, rate := range []int{0, 44100, 22050} {
+		if err := CheckSampleRate(rate); err == nil {
+			t.Errorf("CheckSampleRate(%d): expected error", rate)
+		}
+	}
+}
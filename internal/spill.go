@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// Spill is a FIFO of audio chunks that never drops any: it holds up to
+// memLimit bytes in memory and writes the rest to a temp file, so audio
+// survives a slow connect or a stalled provider. Push and Next may be
+// called from different goroutines.
+type Spill struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	memLimit int
+	mem      [][]byte
+	memBytes int
+	file     *os.File // overflow, created when first needed
+	sizes    []int    // lengths of the chunks waiting in file
+	readOff  int64
+	writeOff int64
+	closed   bool
+	peak     int // most bytes waiting at once
+	waiting  int
+}
+
+// NewSpill returns a spill that keeps up to memLimit bytes in memory.
+func NewSpill(memLimit int) *Spill {
+	s := &Spill{memLimit: memLimit}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// Push adds a chunk. It fails only if the temp file can't be written.
+func (s *Spill) Push(chunk []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Once chunks are in the file, later ones follow them there to keep
+	// the order.
+	if len(s.sizes) == 0 && s.memBytes+len(chunk) <= s.memLimit {
+		s.mem = append(s.mem, chunk)
+		s.memBytes += len(chunk)
+	} else {
+		if s.file == nil {
+			f, err := os.CreateTemp("", "golos-spill-*.pcm")
+			if err != nil {
+				return err
+			}
+			s.file = f
+		}
+		if _, err := s.file.WriteAt(chunk, s.writeOff); err != nil {
+			return err
+		}
+		s.writeOff += int64(len(chunk))
+		s.sizes = append(s.sizes, len(chunk))
+	}
+	s.waiting += len(chunk)
+	s.peak = max(s.peak, s.waiting)
+	s.cond.Signal()
+	return nil
+}
+
+// Next returns the oldest chunk, waiting for one if the spill is empty.
+// It returns io.EOF once the spill is closed and drained.
+func (s *Spill) Next() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.mem) == 0 && len(s.sizes) == 0 && !s.closed {
+		s.cond.Wait()
+	}
+
+	if len(s.mem) > 0 {
+		chunk := s.mem[0]
+		s.mem = s.mem[1:]
+		s.memBytes -= len(chunk)
+		s.waiting -= len(chunk)
+		return chunk, nil
+	}
+	if len(s.sizes) > 0 {
+		chunk := make([]byte, s.sizes[0])
+		if _, err := s.file.ReadAt(chunk, s.readOff); err != nil {
+			return nil, err
+		}
+		s.sizes = s.sizes[1:]
+		s.readOff += int64(len(chunk))
+		s.waiting -= len(chunk)
+		if len(s.sizes) == 0 {
+			// Drained: reuse the file from the start.
+			s.readOff, s.writeOff = 0, 0
+			_ = s.file.Truncate(0)
+		}
+		return chunk, nil
+	}
+	return nil, io.EOF
+}
+
+// Close marks the end of the input. Chunks already pushed can still be
+// read.
+func (s *Spill) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.cond.Broadcast()
+}
+
+// Peak returns the most bytes that were waiting at once, and whether any
+// went to disk.
+func (s *Spill) Peak() (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.peak, s.file != nil
+}
+
+// Waiting returns how many bytes are waiting to be read.
+func (s *Spill) Waiting() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waiting
+}
+
+// Release removes the temp file, if any.
+func (s *Spill) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file != nil {
+		_ = s.file.Close()
+		_ = os.Remove(s.file.Name())
+		s.file = nil
+	}
+	s.mem, s.sizes = nil, nil
+}
@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSpillInMemory(t *testing.T) {
+	s := NewSpill(100)
+	defer s.Release()
+	for _, c := range []string{"ab", "cd", "ef"} {
+		if err := s.Push([]byte(c)); err != nil {
+			t.Fatalf("Push: %v", err)
+		}
+	}
+	if n := s.Waiting(); n != 6 {
+		t.Errorf("Waiting() = %d, want 6", n)
+	}
+	s.Close()
+	got := ""
+	for {
+		chunk, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		got += string(chunk)
+	}
+	if got != "abcdef" {
+		t.Errorf("read %q, want %q", got, "abcdef")
+	}
+	if peak, onDisk := s.Peak(); peak != 6 || onDisk {
+		t.Errorf("Peak() = %d, %v, want 6, false", peak, onDisk)
+	}
+}
+
+func TestSpillOverflowsToFile(t *testing.T) {
+	s := NewSpill(4)
+	for _, c := range []string{"ab", "cd", "ef", "gh"} {
+		if err := s.Push([]byte(c)); err != nil {
+			t.Fatalf("Push: %v", err)
+		}
+	}
+	// Memory has room again, but order must hold: ij follows gh to disk.
+	first, _ := s.Next()
+	if err := s.Push([]byte("ij")); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	got := string(first)
+	for range 4 {
+		chunk, err := s.Next()
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		got += string(chunk)
+	}
+	if got != "abcdefghij" {
+		t.Errorf("read %q, want %q", got, "abcdefghij")
+	}
+	if _, onDisk := s.Peak(); !onDisk {
+		t.Error("Peak() reports nothing on disk")
+	}
+
+	name := s.file.Name()
+	s.Release()
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("spill file %s still exists after Release", name)
+	}
+}
+
+func TestSpillNextWaits(t *testing.T) {
+	s := NewSpill(100)
+	defer s.Release()
+	got := make(chan string)
+	go func() {
+		chunk, _ := s.Next()
+		got <- string(chunk)
+		_, err := s.Next()
+		got <- err.Error()
+	}()
+
+	select {
+	case c := <-got:
+		t.Fatalf("Next returned %q before anything was pushed", c)
+	case <-time.After(20 * time.Millisecond):
+	}
+	_ = s.Push([]byte("ab"))
+	if c := <-got; c != "ab" {
+		t.Errorf("Next() = %q, want %q", c, "ab")
+	}
+	s.Close()
+	if c := <-got; c != io.EOF.Error() {
+		t.Errorf("Next() after Close = %q, want EOF", c)
+	}
+}
@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+func init() {
+	RegisterTransformer("dictionary", newDictionaryStage)
+	RegisterTransformer("format", newFormatStage)
+	RegisterTransformer("casing", newCasingStage)
+	RegisterTransformer("filler", newFillerStage)
+	RegisterTransformer("profanity", newProfanityStage)
+	RegisterTransformer("exec", newExecStage)
+	RegisterTransformer("polish", newPolishStage)
+}
+
+// newDictionaryStage applies the session's dictionary replacements.
+func newDictionaryStage(StageConfig, *Config) (Transformer, error) {
+	return TransformerFunc(func(text string, s Session) (string, error) {
+		if s.Dict == nil {
+			return text, nil
+		}
+		return s.Dict.Replace(text), nil
+	}), nil
+}
+
+// newFormatStage applies the spoken-form transforms enabled under [format].
+func newFormatStage(_ StageConfig, cfg *Config) (Transformer, error) {
+	return TransformerFunc(func(text string, _ Session) (string, error) {
+		return Format(text, cfg.Format), nil
+	}), nil
+}
+
+// newCasingStage applies only casing commands, whatever [format] says.
+func newCasingStage(StageConfig, *Config) (Transformer, error) {
+	return TransformerFunc(func(text string, _ Session) (string, error) {
+		return Format(text, FormatConfig{Casing: true}), nil
+	}), nil
+}
+
+var defaultProfanity = []string{
+	"fuck", "fucking", "fucked", "shit", "shitty", "bullshit",
+	"bitch", "asshole", "bastard", "damn", "crap",
+}
+
+// newProfanityStage masks profanity, keeping the first letter: "shit" →
+// "s***". The stage's words replace the default list.
+func newProfanityStage(stage StageConfig, _ *Config) (Transformer, error) {
+	list := defaultProfanity
+	if len(stage.Words) > 0 {
+		list = stage.Words
+	}
+	mask := stage.Mask
+	if mask == "" {
+		mask = "*"
+	}
+	phrases := compilePhrases(list)
+	return TransformerFunc(func(text string, _ Session) (string, error) {
+		words, tail := splitWords(text)
+		for i := 0; i < len(words); i++ {
+			n := phraseAt(words, i, phrases)
+			for k := i; k < i+n; k++ {
+				lead, core, trail := words[k].split()
+				_, size := utf8.DecodeRuneInString(core)
+				words[k].text = lead + core[:size] + strings.Repeat(mask, utf8.RuneCountInString(core)-1) + trail
+			}
+			if n > 0 {
+				i += n - 1
+			}
+		}
+		return joinWords(words, tail), nil
+	}), nil
+}
+
+// newExecStage pipes the text through a shell command. The session is
+// passed in GOLOS_* environment variables and the command runs in the
+// session's working directory.
+func newExecStage(stage StageConfig, _ *Config) (Transformer, error) {
+	if stage.Command == "" {
+		return nil, fmt.Errorf("command is required")
+	}
+	timeout := 5 * time.Second
+	if stage.Timeout != "" {
+		d, err := time.ParseDuration(stage.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q: %w", stage.Timeout, err)
+		}
+		timeout = d
+	}
+	return TransformerFunc(func(text string, s Session) (string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", stage.Command)
+		cmd.WaitDelay = time.Second // don't wait on children still holding stdout
+		cmd.Dir = s.Dir
+		cmd.Stdin = strings.NewReader(text)
+		cmd.Env = append(os.Environ(),
+			"GOLOS_APP="+s.App.Name,
+			"GOLOS_APP_ID="+s.App.ID,
+			"GOLOS_PROFILE="+s.Profile,
+			"GOLOS_OUTPUT="+s.Output,
+			"GOLOS_DIR="+s.Dir,
+			"GOLOS_LANGUAGE="+s.Language,
+		)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", fmt.Errorf("%w: %s", err, msg)
+			}
+			return "", err
+		}
+		return strings.TrimSuffix(string(out), "\n"), nil
+	}), nil
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"sort"
+	"strings"
+)
+
+// StripTrigger removes a trailing submit trigger phrase such as "send it"
+// from text. Matching ignores casing and punctuation like dictionary
+// phrases do. It reports whether a trigger was found.
+func StripTrigger(text string, triggers []string) (string, bool) {
+	phrases := make([][]string, 0, len(triggers))
+	for _, t := range triggers {
+		if norm := normalizePhrase(t); norm != "" {
+			phrases = append(phrases, strings.Fields(norm))
+		}
+	}
+	sort.SliceStable(phrases, func(i, j int) bool {
+		return len(phrases[i]) > len(phrases[j])
+	})
+
+	// Only real words count towards the end of the utterance.
+	var words []token
+	for _, tok := range tokenize(text) {
+		if tok.norm != "" {
+			words = append(words, tok)
+		}
+	}
+
+	for _, phrase := range phrases {
+		if len(phrase) > len(words) {
+			continue
+		}
+		tail := words[len(words)-len(phrase):]
+		match := true
+		for i, w := range phrase {
+			if tail[i].norm != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			return strings.TrimRight(text[:tail[0].start], " ,;:"), true
+		}
+	}
+	return text, false
+}
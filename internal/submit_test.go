@@ -0,0 +1,39 @@
+package internal
+
+import "testing"
+
+func TestStripTrigger(t *testing.T) {
+	triggers := []string{"send it", "submit"}
+	tests := []struct {
+		in     string
+		want   string
+		submit bool
+	}{
+		{"Fix the login bug. Send it.", "Fix the login bug.", true},
+		{"fix the login bug, submit", "fix the login bug", true},
+		{"Submit.", "", true},
+		{"submit the form please", "submit the form please", false},
+		{"nothing here", "nothing here", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, submit := StripTrigger(tt.in, triggers)
+		if got != tt.want || submit != tt.submit {
+			t.Errorf("StripTrigger(%q) = %q, %v; want %q, %v", tt.in, got, submit, tt.want, tt.submit)
+		}
+	}
+}
+
+func TestStripTriggerNoTriggers(t *testing.T) {
+	got, submit := StripTrigger("send it", nil)
+	if got != "send it" || submit {
+		t.Errorf("got %q, %v; want unchanged", got, submit)
+	}
+}
+
+func TestStripTriggerLongestFirst(t *testing.T) {
+	got, submit := StripTrigger("done and send it", []string{"send it", "and send it"})
+	if got != "done" || !submit {
+		t.Errorf("got %q, %v; want %q, true", got, submit, "done")
+	}
+}
@@ -0,0 +1,272 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// SourceTeam marks entries from the synced team dictionary.
+const SourceTeam = "team"
+
+// TeamSync describes where the shared team dictionary comes from. It is
+// kept in ~/.config/golos/team/sync.toml next to the synced copy.
+type TeamSync struct {
+	Source   string    `toml:"source"`   // git URL, local path or http(s) URL
+	File     string    `toml:"file"`     // dictionary path inside a git repo or directory
+	Interval string    `toml:"interval"` // re-sync period while running, e.g. "1h"
+	SyncedAt time.Time `toml:"synced_at"`
+}
+
+// defaultTeamFiles are tried in a repository or directory when File is
+// not set.
+var defaultTeamFiles = []string{"dictionary.toml", filepath.Join(".golos", "dictionary.toml")}
+
+func teamDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "golos", "team")
+}
+
+func teamDictionaryPath() string {
+	return filepath.Join(teamDir(), "dictionary.toml")
+}
+
+func teamSyncPath() string {
+	return filepath.Join(teamDir(), "sync.toml")
+}
+
+// LoadTeamSync reads the sync settings. It returns nil without error when
+// no team dictionary is configured.
+func LoadTeamSync() (*TeamSync, error) {
+	var t TeamSync
+	if _, err := toml.DecodeFile(teamSyncPath(), &t); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("parsing %s: %w", teamSyncPath(), err)
+	}
+	return &t, nil
+}
+
+func (t *TeamSync) save() error {
+	_ = os.MkdirAll(teamDir(), 0755)
+	f, err := os.Create(teamSyncPath())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	return toml.NewEncoder(f).Encode(t)
+}
+
+// RemoveTeamSync stops syncing and deletes the synced team dictionary.
+func RemoveTeamSync() error {
+	return os.RemoveAll(teamDir())
+}
+
+// Sync fetches the team dictionary from its source, validates it and
+// stores its plain-text words as the team layer. It returns the number of
+// words synced. The source's [commands] are dropped.
+func (t *TeamSync) Sync() (int, error) {
+	tmp, err := os.MkdirTemp("", "golos-team-")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	src, err := t.fetch(tmp)
+	if err != nil {
+		return 0, err
+	}
+	f, err := readDictionaryFile(src)
+	if err != nil {
+		return 0, fmt.Errorf("reading team dictionary: %w", err)
+	}
+
+	_ = os.MkdirAll(teamDir(), 0755)
+	out, err := os.Create(teamDictionaryPath())
+	if err != nil {
+		return 0, err
+	}
+	words := sharedWords(f.Words)
+	if err := toml.NewEncoder(out).Encode(dictionaryFile{Words: words}); err != nil {
+		_ = out.Close()
+		return 0, err
+	}
+	if err := out.Close(); err != nil {
+		return 0, err
+	}
+
+	t.SyncedAt = time.Now()
+	return len(words), t.save()
+}
+
+// fetch copies the source's dictionary file into dir and returns its path.
+func (t *TeamSync) fetch(dir string) (string, error) {
+	switch {
+	case isGitSource(t.Source):
+		repo := filepath.Join(dir, "repo")
+		out, err := exec.Command("git", "clone", "--quiet", "--depth", "1", t.Source, repo).CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("git clone %s: %w: %s", t.Source, err, strings.TrimSpace(string(out)))
+		}
+		return t.findFile(repo)
+	case strings.HasPrefix(t.Source, "http://"), strings.HasPrefix(t.Source, "https://"):
+		return download(t.Source, dir)
+	default:
+		info, err := os.Stat(t.Source)
+		if err != nil {
+			return "", err
+		}
+		if info.IsDir() {
+			return t.findFile(t.Source)
+		}
+		return t.Source, nil
+	}
+}
+
+// findFile locates the dictionary inside a checkout or shared directory.
+func (t *TeamSync) findFile(root string) (string, error) {
+	candidates := defaultTeamFiles
+	if t.File != "" {
+		candidates = []string{t.File}
+	}
+	for _, c := range candidates {
+		p := filepath.Join(root, c)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("no %s in %s", strings.Join(candidates, " or "), t.Source)
+}
+
+// isGitSource reports whether source should be cloned: a remote git URL or
+// a local repository, bare or not.
+func isGitSource(source string) bool {
+	for _, prefix := range []string{"git@", "git://", "ssh://", "file://"} {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	if strings.HasSuffix(source, ".git") {
+		return true
+	}
+	if _, err := os.Stat(filepath.Join(source, ".git")); err == nil {
+		return true
+	}
+	// A bare repository has HEAD and objects at its top level.
+	_, headErr := os.Stat(filepath.Join(source, "HEAD"))
+	_, objErr := os.Stat(filepath.Join(source, "objects"))
+	return headErr == nil && objErr == nil
+}
+
+func download(url, dir string) (string, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	// Keep the extension so the format is detected like a local file.
+	name := "dictionary" + path.Ext(strings.SplitN(url, "?", 2)[0])
+	dst := filepath.Join(dir, name)
+	f, err := os.Create(dst)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+// Period returns the re-sync interval, or 0 if periodic sync is off.
+func (t *TeamSync) Period() time.Duration {
+	interval, err := time.ParseDuration(t.Interval)
+	if err != nil || interval < 0 {
+		return 0
+	}
+	return interval
+}
+
+// Due reports whether the team dictionary should be synced again.
+func (t *TeamSync) Due(now time.Time) bool {
+	interval := t.Period()
+	return interval > 0 && now.Sub(t.SyncedAt) >= interval
+}
+
+// teamPoll is how often a running golos checks whether the team
+// dictionary is due for a re-sync or was changed by `golos dict sync`.
+var teamPoll = 30 * time.Second
+
+// RunTeamSync keeps d's team layer current for as long as the process
+// runs: it re-syncs on the configured interval and reloads the layer
+// whenever the synced copy changes, including from another golos process.
+func RunTeamSync(d *Dictionary) {
+	w := &teamWatcher{d: d, loaded: teamModTime()}
+	for ; ; time.Sleep(teamPoll) {
+		w.check(time.Now())
+	}
+}
+
+// teamWatcher is the state RunTeamSync keeps between checks.
+type teamWatcher struct {
+	d      *Dictionary
+	loaded time.Time // mod time of the team dictionary in d
+	tried  time.Time // last sync attempt, so a failing one waits an interval
+	failed string    // last settings error, reported once
+}
+
+func (w *teamWatcher) check(now time.Time) {
+	t, err := LoadTeamSync()
+	switch {
+	case err != nil:
+		if err.Error() != w.failed {
+			fmt.Fprintf(os.Stderr, "\nTeam dictionary: %v\n", err)
+		}
+		w.failed = err.Error()
+	case t != nil && t.Due(now) && now.Sub(w.tried) >= t.Period():
+		w.failed, w.tried = "", now
+		if _, err := t.Sync(); err != nil {
+			fmt.Fprintf(os.Stderr, "\nTeam dictionary sync: %v\n", err)
+		}
+	default:
+		w.failed = ""
+	}
+	if m := teamModTime(); !m.Equal(w.loaded) {
+		w.loaded = m
+		w.d.ReloadTeam()
+	}
+}
+
+// teamModTime returns when the synced team dictionary was last written,
+// or the zero time if there is none.
+func teamModTime() time.Time {
+	info, err := os.Stat(teamDictionaryPath())
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// ReloadTeam re-reads the plain-text words of the synced team dictionary
+// into the layer below the personal entries.
+func (d *Dictionary) ReloadTeam() {
+	f, err := readDictionaryFile(teamDictionaryPath())
+	if err != nil {
+		f = dictionaryFile{}
+	}
+	d.setBelow(Layer{Source: SourceTeam, Words: sharedWords(f.Words)})
+}
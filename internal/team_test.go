@@ -0,0 +1,217 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// newBareRepo creates a bare repository holding the given files.
+func newBareRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	work := t.TempDir()
+	git(t, work, "init", "--quiet")
+	for name, data := range files {
+		writeFile(t, filepath.Join(work, name), data)
+	}
+	git(t, work, "add", "-A")
+	git(t, work, "commit", "--quiet", "-m", "vocabulary")
+
+	bare := filepath.Join(t.TempDir(), "team.git")
+	git(t, work, "clone", "--quiet", "--bare", work, bare)
+	return bare
+}
+
+func TestTeamSyncFromBareRepo(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	bare := newBareRepo(t, map[string]string{
+		"dictionary.toml": "[words]\n\"cube\" = \"kube\"\n[commands]\n\"ship it\" = \"shell make deploy\"\n",
+	})
+
+	ts := &TeamSync{Source: bare, Interval: "1h"}
+	count, err := ts.Sync()
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("count = %d, want 1 (commands are not synced)", count)
+	}
+	if ts.SyncedAt.IsZero() {
+		t.Error("SyncedAt should be set")
+	}
+
+	saved, err := LoadTeamSync()
+	if err != nil || saved == nil || saved.Source != bare {
+		t.Fatalf("LoadTeamSync = %+v, %v", saved, err)
+	}
+
+	d := LoadDictionary()
+	if got := d.Replace("deploy cube"); got != "deploy kube" {
+		t.Errorf("Replace = %q, want %q", got, "deploy kube")
+	}
+	if _, actions := d.ExtractCommands("ship it"); len(actions) != 0 {
+		t.Errorf("actions = %+v, want team commands ignored", actions)
+	}
+}
+
+func TestTeamSyncFileInRepo(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	bare := newBareRepo(t, map[string]string{
+		"vocab/words.csv": "kind,phrase,value\nword,cube,kube\n",
+	})
+
+	ts := &TeamSync{Source: bare, File: "vocab/words.csv"}
+	if count, err := ts.Sync(); err != nil || count != 1 {
+		t.Fatalf("Sync = %d, %v; want 1 entry", count, err)
+	}
+
+	missing := &TeamSync{Source: bare}
+	if _, err := missing.Sync(); err == nil {
+		t.Error("expected error when the repo has no dictionary.toml")
+	}
+}
+
+func TestTeamSyncFromLocalFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "share", "team.json")
+	writeFile(t, path, `{"words": {"cube": "kube"}}`)
+
+	ts := &TeamSync{Source: path}
+	if count, err := ts.Sync(); err != nil || count != 1 {
+		t.Fatalf("Sync = %d, %v; want 1 entry", count, err)
+	}
+}
+
+func TestTeamSyncDropsKeystrokes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "team.json")
+	writeFile(t, path, `{"words": {"cube": "kube", "deploy": "make deploy\n", "paste": "{clipboard}"}}`)
+
+	ts := &TeamSync{Source: path}
+	if count, err := ts.Sync(); err != nil || count != 1 {
+		t.Fatalf("Sync = %d, %v; want only the plain-text word", count, err)
+	}
+}
+
+func TestTeamWatcherReloadsAfterSync(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	d := LoadDictionary()
+	w := &teamWatcher{d: d, loaded: teamModTime()}
+
+	// Synced by `golos dict sync` in another process.
+	path := filepath.Join(t.TempDir(), "team.toml")
+	writeFile(t, path, "[words]\n\"cube\" = \"kube\"\n")
+	if _, err := (&TeamSync{Source: path}).Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	w.check(time.Now())
+	if got := d.Replace("cube"); got != "kube" {
+		t.Errorf("Replace = %q, want the new team word", got)
+	}
+
+	if err := RemoveTeamSync(); err != nil {
+		t.Fatalf("RemoveTeamSync: %v", err)
+	}
+	w.check(time.Now())
+	if got := d.Replace("cube"); got != "cube" {
+		t.Errorf("Replace = %q, want the team layer gone", got)
+	}
+}
+
+func TestTeamLayerBelowPersonal(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	writeFile(t, teamDictionaryPath(), "[words]\n\"period\" = \"TEAM\"\n\"cube\" = \"kube\"\n")
+
+	d := LoadDictionary()
+	if err := d.Add("period", "."); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if got := d.Replace("cube period"); got != "kube ." {
+		t.Errorf("Replace = %q, want %q", got, "kube .")
+	}
+
+	sources := map[string]string{}
+	for _, e := range d.Entries() {
+		sources[e.Phrase] = e.Source
+	}
+	if sources["period"] != SourcePersonal || sources["cube"] != SourceTeam {
+		t.Errorf("sources = %v", sources)
+	}
+
+	// The team layer is never written to the personal dictionary.
+	if _, ok := d.List()["cube"]; ok {
+		t.Error("team entries leaked into personal entries")
+	}
+}
+
+func TestIsGitSource(t *testing.T) {
+	tests := map[string]bool{
+		"git@github.com:team/vocab.git":     true,
+		"https://github.com/team/vocab.git": true,
+		"ssh://git.example.com/vocab":       true,
+		"file:///srv/vocab":                 true,
+		"https://example.com/dict.toml":     false,
+		t.TempDir():                         false,
+	}
+	for src, want := range tests {
+		if got := isGitSource(src); got != want {
+			t.Errorf("isGitSource(%q) = %v, want %v", src, got, want)
+		}
+	}
+}
+
+func TestTeamSyncDue(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		ts   TeamSync
+		want bool
+	}{
+		{TeamSync{Interval: "1h", SyncedAt: now.Add(-2 * time.Hour)}, true},
+		{TeamSync{Interval: "1h", SyncedAt: now.Add(-time.Minute)}, false},
+		{TeamSync{Interval: "0", SyncedAt: now.Add(-48 * time.Hour)}, false},
+		{TeamSync{Interval: "", SyncedAt: now.Add(-48 * time.Hour)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.ts.Due(now); got != tt.want {
+			t.Errorf("Due(%+v) = %v, want %v", tt.ts, got, tt.want)
+		}
+	}
+}
+
+func TestRemoveTeamSync(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	writeFile(t, teamDictionaryPath(), "[words]\n\"cube\" = \"kube\"\n")
+	if err := RemoveTeamSync(); err != nil {
+		t.Fatalf("RemoveTeamSync: %v", err)
+	}
+	if ts, err := LoadTeamSync(); ts != nil || err != nil {
+		t.Errorf("LoadTeamSync = %+v, %v; want nil", ts, err)
+	}
+	if len(LoadDictionary().Entries()) != 0 {
+		t.Error("team entries should be gone")
+	}
+}
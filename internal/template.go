@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+)
+
+// TemplateVars supplies the values for snippet placeholders in dictionary
+// replacements. Clipboard and Branch are only called when the template
+// uses them.
+type TemplateVars struct {
+	Rest      string
+	Now       time.Time
+	Clipboard func() string
+	Branch    func() string
+}
+
+var placeholderRe = regexp.MustCompile(`\{(rest|date|time|clipboard|branch)\}`)
+
+// defaultTemplateVars reads the real clock, clipboard and the git branch
+// checked out in dir.
+func defaultTemplateVars(dir string) TemplateVars {
+	return TemplateVars{
+		Now: time.Now(),
+		Clipboard: func() string {
+			text, _ := clipboard.ReadAll()
+			return text
+		},
+		Branch: func() string {
+			cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+			cmd.Dir = dir
+			out, err := cmd.Output()
+			if err != nil {
+				return ""
+			}
+			return strings.TrimSpace(string(out))
+		},
+	}
+}
+
+// hasRest reports whether a replacement captures the words spoken after
+// its trigger phrase.
+func hasRest(tmpl string) bool {
+	return strings.Contains(tmpl, "{rest}")
+}
+
+// ExpandTemplate fills in the {rest}, {date}, {time}, {clipboard} and
+// {branch} placeholders. Any other text in braces is left as is, so plain
+// replacements like "{" are unaffected.
+func ExpandTemplate(tmpl string, vars TemplateVars) string {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+	return placeholderRe.ReplaceAllStringFunc(tmpl, func(m string) string {
+		switch m {
+		case "{rest}":
+			return vars.Rest
+		case "{date}":
+			return vars.Now.Format("2006-01-02")
+		case "{time}":
+			return vars.Now.Format("15:04")
+		case "{clipboard}":
+			if vars.Clipboard != nil {
+				return vars.Clipboard()
+			}
+		case "{branch}":
+			if vars.Branch != nil {
+				return vars.Branch()
+			}
+		}
+		return ""
+	})
+}
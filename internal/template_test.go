@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func testVars() TemplateVars {
+	return TemplateVars{
+		Rest:      "fix login bug",
+		Now:       time.Date(2026, 3, 7, 9, 5, 0, 0, time.UTC),
+		Clipboard: func() string { return "copied" },
+		Branch:    func() string { return "main" },
+	}
+}
+
+func TestExpandTemplate(t *testing.T) {
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{`git commit -m "{rest}"`, `git commit -m "fix login bug"`},
+		{"notes-{date}.md", "notes-2026-03-07.md"},
+		{"at {time}", "at 09:05"},
+		{"paste: {clipboard}", "paste: copied"},
+		{"git push origin {branch}", "git push origin main"},
+		{"{", "{"},
+		{"{unknown} stays", "{unknown} stays"},
+		{"no placeholders", "no placeholders"},
+	}
+	for _, tt := range tests {
+		if got := ExpandTemplate(tt.tmpl, testVars()); got != tt.want {
+			t.Errorf("ExpandTemplate(%q) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
+
+func TestExpandTemplateLazyVars(t *testing.T) {
+	called := false
+	vars := TemplateVars{Branch: func() string { called = true; return "x" }}
+	ExpandTemplate("{date}", vars)
+	if called {
+		t.Error("Branch should not be called when {branch} is unused")
+	}
+}
+
+func TestExpandTemplateNilFuncs(t *testing.T) {
+	if got := ExpandTemplate("[{clipboard}{branch}]", TemplateVars{}); got != "[]" {
+		t.Errorf("got %q, want %q", got, "[]")
+	}
+}
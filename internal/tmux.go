@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// TmuxMode sends the text to a tmux pane with send-keys. An empty Target
+// addresses the current pane.
+type TmuxMode struct {
+	Target string
+}
+
+func (t *TmuxMode) Deliver(text string) error {
+	return t.sendKeys("-l", "--", text)
+}
+
+// Submit presses Enter in the target pane.
+func (t *TmuxMode) Submit() error {
+	return t.sendKeys("Enter")
+}
+
+func (t *TmuxMode) args(keys ...string) []string {
+	args := []string{"send-keys"}
+	if t.Target != "" {
+		args = append(args, "-t", t.Target)
+	}
+	return append(args, keys...)
+}
+
+func (t *TmuxMode) sendKeys(keys ...string) error {
+	out, err := exec.Command("tmux", t.args(keys...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("tmux send-keys: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// TmuxPaneDir returns the current working directory of the target pane, or
+// of the active pane when target is empty.
+func TmuxPaneDir(target string) (string, error) {
+	args := []string{"display-message", "-p"}
+	if target != "" {
+		args = append(args, "-t", target)
+	}
+	out, err := exec.Command("tmux", append(args, "#{pane_current_path}")...).Output()
+	if err != nil {
+		return "", fmt.Errorf("tmux display-message: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
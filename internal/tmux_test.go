@@ -0,0 +1,24 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTmuxArgsWithTarget(t *testing.T) {
+	m := &TmuxMode{Target: "work:1.0"}
+	got := m.args("-l", "--", "hello")
+	want := []string{"send-keys", "-t", "work:1.0", "-l", "--", "hello"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("args = %v, want %v", got, want)
+	}
+}
+
+func TestTmuxArgsCurrentPane(t *testing.T) {
+	m := &TmuxMode{}
+	got := m.args("Enter")
+	want := []string{"send-keys", "Enter"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("args = %v, want %v", got, want)
+	}
+}
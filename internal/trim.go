@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+)
+
+// TrimConfig controls how much silence is streamed to the provider.
+type TrimConfig struct {
+	Enabled  bool   `toml:"enabled"`   // hold back silence before speech and drop it after; off by default
+	Pad      string `toml:"pad"`       // audio kept before speech starts, e.g. "200ms"
+	MaxPause string `toml:"max_pause"` // pauses longer than this are shortened, e.g. "1s"; "" keeps them
+}
+
+// Trimmer decides which frames of a session are worth streaming, from
+// the VAD's verdict on each. Silence before the first word is held back
+// except for a short pad, silence after the last word is dropped once
+// the VAD's hangover has passed, and with a maximum pause set, long
+// pauses between words are cut down to the hangover plus the pad.
+type Trimmer struct {
+	pad      int // frames
+	maxPause int // frames; 0 keeps pauses whole
+
+	speaking bool
+	spoken   bool      // speech has started this session
+	held     [][]int16 // silence not sent yet
+	pause    int       // frames in the current pause
+}
+
+// NewTrimmer returns a trimmer for the config, or nil if trimming is off.
+func NewTrimmer(cfg TrimConfig) (*Trimmer, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	t := &Trimmer{}
+	if cfg.Pad != "" {
+		d, err := time.ParseDuration(cfg.Pad)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("trim pad: invalid duration %q", cfg.Pad)
+		}
+		t.pad = int(d / (FrameDurMs * time.Millisecond))
+	}
+	if cfg.MaxPause != "" {
+		d, err := time.ParseDuration(cfg.MaxPause)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("trim max_pause: invalid duration %q", cfg.MaxPause)
+		}
+		t.maxPause = int(d / (FrameDurMs * time.Millisecond))
+	}
+	return t, nil
+}
+
+// Push takes the next frame and what the VAD made of it, and returns the
+// frames to send now, oldest first.
+func (t *Trimmer) Push(frame []int16, ev VADEvent) [][]int16 {
+	switch {
+	case ev == SpeechStart:
+		out := append(t.lead(), frame)
+		t.held, t.pause = nil, 0
+		t.speaking, t.spoken = true, true
+		return out
+	case t.speaking:
+		// The VAD's hangover frames are sent as speech, so the end of a
+		// word is never cut; SpeechEnd comes with the last of them.
+		if ev == SpeechEnd {
+			t.speaking = false
+		}
+		return [][]int16{frame}
+	}
+
+	t.held = append(t.held, frame)
+	t.pause++
+	// Keep only what lead could still send.
+	if !t.spoken || (t.maxPause > 0 && t.pause > t.maxPause) {
+		if n := len(t.held) - t.pad; n > 0 {
+			t.held = append(t.held[:0], t.held[n:]...)
+		}
+	}
+	return nil
+}
+
+// lead returns the held silence to send before speech: the whole pause
+// if it was short enough to keep, otherwise the pad.
+func (t *Trimmer) lead() [][]int16 {
+	if t.spoken && (t.maxPause == 0 || t.pause <= t.maxPause) {
+		return t.held
+	}
+	return t.held[max(0, len(t.held)-t.pad):]
+}
+
+// Reset starts a new session.
+func (t *Trimmer) Reset() {
+	t.speaking, t.spoken = false, false
+	t.held, t.pause = nil, 0
+}
@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"fmt"
+	"testing"
+)
+
+// trimFrames pushes frames numbered from 0 through a trimmer, with
+// events given one character per frame: S speech start, E speech end,
+// anything else no event. It returns the numbers of the frames sent.
+func trimFrames(t *Trimmer, events string) string {
+	out := ""
+	for i, c := range events {
+		ev := VADNone
+		switch c {
+		case 'S':
+			ev = SpeechStart
+		case 'E':
+			ev = SpeechEnd
+		}
+		for _, f := range t.Push([]int16{int16(i)}, ev) {
+			out += fmt.Sprint(f[0], " ")
+		}
+	}
+	return out
+}
+
+func newTestTrimmer(t *testing.T, pad, maxPause string) *Trimmer {
+	t.Helper()
+	tr, err := NewTrimmer(TrimConfig{Enabled: true, Pad: pad, MaxPause: maxPause})
+	if err != nil {
+		t.Fatalf("NewTrimmer: %v", err)
+	}
+	return tr
+}
+
+func TestTrimmerLeadingAndTrailing(t *testing.T) {
+	tr := newTestTrimmer(t, "40ms", "")
+	// Two frames of pad before speech; nothing after the hangover.
+	if got := trimFrames(tr, "_____S__E___"); got != "3 4 5 6 7 8 " {
+		t.Errorf("sent %q", got)
+	}
+}
+
+func TestTrimmerKeepsPauses(t *testing.T) {
+	tr := newTestTrimmer(t, "20ms", "")
+	if got := trimFrames(tr, "S_E_____S_E__"); got != "0 1 2 3 4 5 6 7 8 9 10 " {
+		t.Errorf("sent %q", got)
+	}
+}
+
+func TestTrimmerShortensLongPauses(t *testing.T) {
+	tr := newTestTrimmer(t, "20ms", "60ms")
+	// A three-frame pause is kept; a five-frame one is cut to the pad.
+	if got := trimFrames(tr, "SE___SE_____SE"); got != "0 1 2 3 4 5 6 11 12 13 " {
+		t.Errorf("sent %q", got)
+	}
+}
+
+func TestTrimmerReset(t *testing.T) {
+	tr := newTestTrimmer(t, "20ms", "")
+	trimFrames(tr, "S_E__")
+	tr.Reset()
+	if got := trimFrames(tr, "___"); got != "" {
+		t.Errorf("sent %q after Reset, want nothing before speech", got)
+	}
+}
+
+func TestNewTrimmer(t *testing.T) {
+	if tr, err := NewTrimmer(TrimConfig{Pad: "200ms"}); tr != nil || err != nil {
+		t.Errorf("NewTrimmer(disabled) = %v, %v, want nil, nil", tr, err)
+	}
+	for _, cfg := range []TrimConfig{{Enabled: true, Pad: "soon"}, {Enabled: true, Pad: "-1s"}, {Enabled: true, MaxPause: "0s"}} {
+		if _, err := NewTrimmer(cfg); err == nil {
+			t.Errorf("NewTrimmer(%+v): expected error", cfg)
+		}
+	}
+}
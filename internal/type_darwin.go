@@ -0,0 +1,52 @@
+package internal
+
+/*
+#cgo LDFLAGS: -framework ApplicationServices
+#include <ApplicationServices/ApplicationServices.h>
+#include <unistd.h>
+
+// typeUnicode posts the string as synthetic key events. Each event carries
+// at most 20 UTF-16 units, the limit CGEventKeyboardSetUnicodeString honors.
+void typeUnicode(const UniChar *chars, int n) {
+    for (int i = 0; i < n; i += 20) {
+        int len = n - i < 20 ? n - i : 20;
+
+        CGEventRef down = CGEventCreateKeyboardEvent(NULL, 0, true);
+        CGEventKeyboardSetUnicodeString(down, len, chars + i);
+        CGEventPost(kCGHIDEventTap, down);
+        CFRelease(down);
+
+        CGEventRef up = CGEventCreateKeyboardEvent(NULL, 0, false);
+        CGEventKeyboardSetUnicodeString(up, len, chars + i);
+        CGEventPost(kCGHIDEventTap, up);
+        CFRelease(up);
+        usleep(2000);
+    }
+}
+*/
+import "C"
+
+import (
+	"time"
+	"unicode/utf16"
+	"unsafe"
+)
+
+// TypeMode types the text into the focused application as keystrokes,
+// leaving the clipboard untouched.
+type TypeMode struct{}
+
+func (t *TypeMode) Deliver(text string) error {
+	units := utf16.Encode([]rune(text))
+	if len(units) == 0 {
+		return nil
+	}
+	C.typeUnicode((*C.UniChar)(unsafe.Pointer(&units[0])), C.int(len(units)))
+	return nil
+}
+
+// Submit presses Return after the typed text.
+func (t *TypeMode) Submit() error {
+	time.Sleep(20 * time.Millisecond)
+	return SendChord(Chord{Key: "return", Code: keyCodes["return"]})
+}
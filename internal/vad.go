@@ -1,101 +1,188 @@
 package internal
 
 import (
-	"encoding/binary"
+	"fmt"
 	"sync"
-
-	webrtcvad "github.com/maxhawkins/go-webrtcvad"
+	"time"
 )
 
 type VADEvent int
 
 const (
-	VADNone        VADEvent = iota
+	VADNone VADEvent = iota
 	SpeechStart
 	SpeechEnd
 )
 
-type Detector struct {
-	mu             sync.Mutex
-	vad            *webrtcvad.VAD
-	sampleRate     int
-	hangoverFrames int
-	active         bool
-	silentCount    int
+// Detector is a voice activity detector. It is fed the session's frames
+// in order and reports where speech starts and ends.
+type Detector interface {
+	// Process takes a frame of int16 samples and returns the VAD event
+	// it caused, if any.
+	Process(frame []int16) (VADEvent, error)
+	// IsActive returns whether speech is currently detected.
+	IsActive() bool
+	// Voiced returns how long the audio has been voiced without a break,
+	// up to the last frame, in 20ms frames.
+	Voiced() int
+	// Reset resets the VAD state.
+	Reset()
 }
 
-// NewDetector creates a VAD with the given hangover duration in milliseconds.
-// mode: 0 (least aggressive) to 3 (most aggressive).
-func NewDetector(sampleRate, hangoverMs, mode int) (*Detector, error) {
-	v, err := webrtcvad.New()
-	if err != nil {
-		return nil, err
+// VADConfig selects and tunes the voice activity detector.
+type VADConfig struct {
+	Engine   string `toml:"engine"`   // "webrtc" or "energy"; empty picks webrtc where it's built in
+	Mode     int    `toml:"mode"`     // aggressiveness, 0 (least) to 3 (most)
+	Hangover string `toml:"hangover"` // silence after speech before it counts as ended, e.g. "300ms"
+	FrameMs  int    `toml:"frame_ms"` // analysis window: 10, 20 or 30 ms
+}
+
+// NewVAD returns the detector the config selects for audio at rate.
+func NewVAD(cfg VADConfig, rate int) (Detector, error) {
+	hangover := 300 * time.Millisecond
+	if cfg.Hangover != "" {
+		d, err := time.ParseDuration(cfg.Hangover)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("vad hangover: invalid duration %q", cfg.Hangover)
+		}
+		hangover = d
+	}
+	frameMs := cfg.FrameMs
+	if frameMs == 0 {
+		frameMs = FrameDurMs
 	}
-	if err := v.SetMode(mode); err != nil {
-		return nil, err
+	if frameMs != 10 && frameMs != 20 && frameMs != 30 {
+		return nil, fmt.Errorf("vad frame_ms %d: must be 10, 20 or 30", frameMs)
 	}
+	if cfg.Mode < 0 || cfg.Mode > 3 {
+		return nil, fmt.Errorf("vad mode %d: must be 0 to 3", cfg.Mode)
+	}
+	hangoverMs := int(hangover / time.Millisecond)
+
+	switch cfg.Engine {
+	case "", "webrtc":
+		if !webrtcAvailable {
+			if cfg.Engine == "" {
+				return NewEnergyDetector(rate, hangoverMs, cfg.Mode, frameMs), nil
+			}
+			return nil, fmt.Errorf("vad engine webrtc: not built in (built with nowebrtcvad); use engine = \"energy\"")
+		}
+		d, err := newWebRTCDetector(rate, hangoverMs, cfg.Mode, frameMs)
+		if err != nil {
+			return nil, err
+		}
+		return d, nil
+	case "energy":
+		return NewEnergyDetector(rate, hangoverMs, cfg.Mode, frameMs), nil
+	}
+	return nil, fmt.Errorf("vad engine %q: must be webrtc or energy", cfg.Engine)
+}
+
+// speechState turns per-window voiced/silent verdicts into speech start
+// and end events, holding speech open through a hangover of silence.
+// Frames are cut into windows of the engine's size as they arrive.
+type speechState struct {
+	mu             sync.Mutex
+	windowMs       int
+	window         int // samples per window
+	hangoverFrames int // hangover in windows
+	active         bool
+	silentCount    int
+	voiced         int  // consecutive voiced windows
+	endPending     bool // speech ended in the frame it started in
+	pending        []int16
+}
 
-	frameDurMs := 20
-	hangoverFrames := hangoverMs / frameDurMs
+func newSpeechState(sampleRate, hangoverMs, windowMs int) speechState {
+	hangoverFrames := hangoverMs / windowMs
 	if hangoverFrames < 1 {
 		hangoverFrames = 1
 	}
-
-	return &Detector{
-		vad:            v,
-		sampleRate:     sampleRate,
+	return speechState{
+		windowMs:       windowMs,
+		window:         sampleRate * windowMs / 1000,
 		hangoverFrames: hangoverFrames,
-	}, nil
+	}
 }
 
-// Process takes a 20ms frame of int16 samples and returns the VAD event.
-func (d *Detector) Process(frame []int16) (VADEvent, error) {
-	// Convert int16 slice to bytes for go-webrtcvad
-	buf := make([]byte, len(frame)*2)
-	for i, s := range frame {
-		binary.LittleEndian.PutUint16(buf[i*2:], uint16(s))
-	}
+// process runs isVoiced over each complete window in frame and returns
+// the event they caused. A start wins over an end in the same frame, so
+// a short burst isn't lost; its end is reported with the next frame.
+func (s *speechState) process(frame []int16, isVoiced func(window []int16) (bool, error)) (VADEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	active, err := d.vad.Process(d.sampleRate, buf)
-	if err != nil {
-		return VADNone, err
+	s.pending = append(s.pending, frame...)
+	ev := VADNone
+	if s.endPending {
+		ev, s.endPending = SpeechEnd, false
 	}
+	started := false
+	n := 0
+	for ; len(s.pending)-n >= s.window; n += s.window {
+		voiced, err := isVoiced(s.pending[n : n+s.window])
+		if err != nil {
+			s.pending = s.pending[:0]
+			return VADNone, err
+		}
+		switch e := s.update(voiced); e {
+		case SpeechStart:
+			started, ev = true, e
+		case SpeechEnd:
+			if !started {
+				ev = e
+			}
+		}
+	}
+	s.pending = append(s.pending[:0], s.pending[n:]...)
+	if started && !s.active {
+		s.endPending = true
+	}
+	return ev, nil
+}
 
-	d.mu.Lock()
-	defer d.mu.Unlock()
-
-	if active {
-		d.silentCount = 0
-		if !d.active {
-			d.active = true
-			return SpeechStart, nil
+func (s *speechState) update(voiced bool) VADEvent {
+	if voiced {
+		s.voiced++
+		s.silentCount = 0
+		if !s.active {
+			s.active = true
+			return SpeechStart
 		}
-		return VADNone, nil
+		return VADNone
 	}
 
-	// Frame is silent
-	if d.active {
-		d.silentCount++
-		if d.silentCount >= d.hangoverFrames {
-			d.active = false
-			d.silentCount = 0
-			return SpeechEnd, nil
+	// Window is silent
+	s.voiced = 0
+	if s.active {
+		s.silentCount++
+		if s.silentCount >= s.hangoverFrames {
+			s.active = false
+			s.silentCount = 0
+			return SpeechEnd
 		}
 	}
-	return VADNone, nil
+	return VADNone
+}
+
+func (s *speechState) IsActive() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active
 }
 
-// IsActive returns whether speech is currently detected.
-func (d *Detector) IsActive() bool {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-	return d.active
+func (s *speechState) Voiced() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.voiced * s.windowMs / FrameDurMs
 }
 
-// Reset resets the VAD state.
-func (d *Detector) Reset() {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-	d.active = false
-	d.silentCount = 0
+func (s *speechState) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active = false
+	s.silentCount = 0
+	s.voiced = 0
+	s.endPending = false
+	s.pending = s.pending[:0]
 }
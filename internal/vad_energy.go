@@ -0,0 +1,72 @@
+package internal
+
+import "math"
+
+// EnergyDetector is a pure-Go VAD for builds without WebRTC's. A window is
+// voiced when its level rises clearly above the tracked background and
+// its zero-crossing rate is low enough for voice rather than hiss; very
+// loud windows count whatever their crossing rate, for sibilants.
+type EnergyDetector struct {
+	speechState
+	threshold float64 // dB above the background
+	rise      float64 // dB per window the background estimate creeps up
+	floor     float64 // background level in dB; NaN until the first window
+}
+
+const (
+	energyMinDB    = 30   // about -60 dBFS; quieter is never speech
+	energyStartDB  = 40   // the background is assumed no louder than this at first
+	energyMaxZCR   = 0.35 // crossings per sample; above this sounds like noise
+	energyFloorDBs = 3    // how fast the background estimate rises, in dB/s
+)
+
+// NewEnergyDetector creates an energy/zero-crossing VAD with the given
+// hangover in milliseconds and window size. mode: 0 (least aggressive) to
+// 3 (most aggressive) sets how far above the background speech must be.
+func NewEnergyDetector(sampleRate, hangoverMs, mode, windowMs int) *EnergyDetector {
+	return &EnergyDetector{
+		speechState: newSpeechState(sampleRate, hangoverMs, windowMs),
+		threshold:   6 + 3*float64(mode),
+		rise:        energyFloorDBs * float64(windowMs) / 1000,
+		floor:       math.NaN(),
+	}
+}
+
+// Process takes a frame of int16 samples and returns the VAD event.
+func (d *EnergyDetector) Process(frame []int16) (VADEvent, error) {
+	return d.process(frame, func(window []int16) (bool, error) {
+		return d.isVoiced(window), nil
+	})
+}
+
+// isVoiced judges a window and updates the background estimate. Called
+// with d.mu held.
+func (d *EnergyDetector) isVoiced(window []int16) bool {
+	var sum float64
+	crossings := 0
+	for i, s := range window {
+		sum += float64(s) * float64(s)
+		if i > 0 && (s >= 0) != (window[i-1] >= 0) {
+			crossings++
+		}
+	}
+	level := 20 * math.Log10(math.Sqrt(sum/float64(len(window)))+1)
+	zcr := float64(crossings) / float64(len(window))
+
+	if math.IsNaN(d.floor) {
+		d.floor = min(level, energyStartDB)
+	}
+	above := level - d.floor
+	// The background follows the level down quickly and creeps up
+	// slowly, so it settles on the quiet between words.
+	if level < d.floor {
+		d.floor += (level - d.floor) * 0.3
+	} else {
+		d.floor += d.rise
+	}
+
+	if level < energyMinDB || above < d.threshold {
+		return false
+	}
+	return zcr < energyMaxZCR || above >= 2*d.threshold
+}
@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"math"
+	"testing"
+)
+
+func newTestEnergyDetector(hangoverMs int) *EnergyDetector {
+	return NewEnergyDetector(testSampleRate, hangoverMs, 3, 20)
+}
+
+// feed processes frames and returns the events they caused.
+func feed(d Detector, frames [][]int16) []VADEvent {
+	var events []VADEvent
+	for _, f := range frames {
+		if ev, _ := d.Process(f); ev != VADNone {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+func repeat(frame func() []int16, n int) [][]int16 {
+	out := make([][]int16, n)
+	for i := range out {
+		out[i] = frame()
+	}
+	return out
+}
+
+func TestEnergySilence(t *testing.T) {
+	d := newTestEnergyDetector(300)
+	if events := feed(d, repeat(silentFrame, 50)); len(events) != 0 {
+		t.Errorf("events = %v from silence, want none", events)
+	}
+}
+
+func TestEnergyFullCycle(t *testing.T) {
+	d := newTestEnergyDetector(60)
+	frames := append(repeat(silentFrame, 5), repeat(loudFrame, 10)...)
+	frames = append(frames, repeat(silentFrame, 10)...)
+	events := feed(d, frames)
+	if len(events) != 2 || events[0] != SpeechStart || events[1] != SpeechEnd {
+		t.Errorf("events = %v, want SpeechStart, SpeechEnd", events)
+	}
+	if d.IsActive() {
+		t.Error("detector should not be active after SpeechEnd")
+	}
+}
+
+func TestEnergyQuietSpeech(t *testing.T) {
+	d := newTestEnergyDetector(300)
+	feed(d, repeat(silentFrame, 10))
+	events := feed(d, tone(200, 300, 5))
+	if len(events) != 1 || events[0] != SpeechStart {
+		t.Errorf("events = %v for quiet speech, want SpeechStart", events)
+	}
+	if d.Voiced() != 5 {
+		t.Errorf("Voiced() = %d, want 5", d.Voiced())
+	}
+}
+
+func TestEnergyIgnoresSteadyNoise(t *testing.T) {
+	d := newTestEnergyDetector(300)
+	if events := feed(d, noise(1000, 100)); len(events) != 0 {
+		t.Errorf("events = %v from steady noise, want none", events)
+	}
+	// Speech over the same noise is still heard.
+	if events := feed(d, mix(tone(200, 8000, 10), noise(1000, 10))); len(events) != 1 || events[0] != SpeechStart {
+		t.Errorf("events = %v for speech over noise, want SpeechStart", events)
+	}
+}
+
+func TestEnergyModeSetsThreshold(t *testing.T) {
+	// A tone 10 dB above steady noise: speech to mode 0, not to mode 3.
+	noiseLevel := 300 / math.Sqrt(3)
+	amp := noiseLevel * math.Sqrt2 * fromDB(10)
+	for mode, want := range map[int]bool{0: true, 3: false} {
+		d := NewEnergyDetector(testSampleRate, 300, mode, 20)
+		feed(d, noise(300, 50))
+		feed(d, tone(200, amp, 5))
+		if d.IsActive() != want {
+			t.Errorf("mode %d: active = %v, want %v", mode, d.IsActive(), want)
+		}
+	}
+}
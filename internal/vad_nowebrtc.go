@@ -0,0 +1,13 @@
+//go:build nowebrtcvad
+
+package internal
+
+import "errors"
+
+// Built with -tags nowebrtcvad, go-webrtcvad is left out and the energy
+// detector is used.
+const webrtcAvailable = false
+
+func newWebRTCDetector(sampleRate, hangoverMs, mode, windowMs int) (Detector, error) {
+	return nil, errors.New("webrtc VAD not built in")
+}
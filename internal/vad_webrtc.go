@@ -0,0 +1,51 @@
+//go:build !nowebrtcvad
+
+package internal
+
+import (
+	"encoding/binary"
+
+	webrtcvad "github.com/maxhawkins/go-webrtcvad"
+)
+
+const webrtcAvailable = true
+
+// WebRTCDetector is the VAD from WebRTC, through go-webrtcvad.
+type WebRTCDetector struct {
+	speechState
+	vad        *webrtcvad.VAD
+	sampleRate int
+}
+
+// NewDetector creates a VAD with the given hangover duration in milliseconds.
+// mode: 0 (least aggressive) to 3 (most aggressive).
+func NewDetector(sampleRate, hangoverMs, mode int) (*WebRTCDetector, error) {
+	return newWebRTCDetector(sampleRate, hangoverMs, mode, FrameDurMs)
+}
+
+func newWebRTCDetector(sampleRate, hangoverMs, mode, windowMs int) (*WebRTCDetector, error) {
+	v, err := webrtcvad.New()
+	if err != nil {
+		return nil, err
+	}
+	if err := v.SetMode(mode); err != nil {
+		return nil, err
+	}
+	return &WebRTCDetector{
+		speechState: newSpeechState(sampleRate, hangoverMs, windowMs),
+		vad:         v,
+		sampleRate:  sampleRate,
+	}, nil
+}
+
+// Process takes a frame of int16 samples and returns the VAD event.
+func (d *WebRTCDetector) Process(frame []int16) (VADEvent, error) {
+	return d.process(frame, func(window []int16) (bool, error) {
+		// Convert int16 slice to bytes for go-webrtcvad
+		buf := make([]byte, len(window)*2)
+		for i, s := range window {
+			binary.LittleEndian.PutUint16(buf[i*2:], uint16(s))
+		}
+		return d.vad.Process(d.sampleRate, buf)
+	})
+}
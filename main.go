@@ -37,11 +37,29 @@ func main() {
 			cli.DictDelete(os.Args[2:])
 			return
 		case "list":
-			cli.DictList()
+			cli.DictList(os.Args[2:])
 			return
 		case "import":
 			cli.DictImport(os.Args[2:])
 			return
+		case "export":
+			cli.DictExport(os.Args[2:])
+			return
+		case "correct":
+			cli.Correct()
+			return
+		case "dict":
+			cli.Dict(os.Args[2:])
+			return
+		case "profile":
+			cli.Profile(os.Args[2:])
+			return
+		case "hotkey":
+			cli.Hotkey(os.Args[2:])
+			return
+		case "replay":
+			cli.Replay(os.Args[2:])
+			return
 		case "setup":
 			cli.Setup()
 			return
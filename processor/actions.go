@@ -0,0 +1,110 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/basilysf1709/golos/internal"
+)
+
+// dispatcher executes the voice command actions recognized in a transcript.
+// The side effects are held as functions so tests can observe them.
+type dispatcher struct {
+	p              *Processor
+	sendChord      func(internal.Chord) error
+	deleteBackward func(n int) error
+	runShell       func(command string) error
+}
+
+func newDispatcher(p *Processor) *dispatcher {
+	return &dispatcher{
+		p:              p,
+		sendChord:      internal.SendChord,
+		deleteBackward: internal.DeleteBackward,
+		runShell:       startShell,
+	}
+}
+
+// run executes the actions belonging to one side of delivery: those that
+// must happen before the text is delivered, or those that follow it. out is
+// the output mode the session delivers to.
+func (d *dispatcher) run(actions []internal.Action, beforeDelivery bool, out internal.OutputMode) {
+	for _, a := range actions {
+		if a.BeforeDelivery() != beforeDelivery {
+			continue
+		}
+		if err := d.exec(a, out); err != nil {
+			fmt.Fprintf(os.Stderr, "Command %s error: %v\n", a.Kind, err)
+		}
+	}
+}
+
+func (d *dispatcher) exec(a internal.Action, out internal.OutputMode) error {
+	switch a.Kind {
+	case internal.ActionEnter:
+		if s, ok := out.(internal.Submitter); ok {
+			return s.Submit()
+		}
+		ch, _ := internal.ParseChord("return")
+		return d.sendChord(ch)
+	case internal.ActionKey:
+		return d.sendChord(a.Chord)
+	case internal.ActionUndo:
+		return d.undo()
+	case internal.ActionOutput:
+		return d.switchOutput(a.Arg)
+	case internal.ActionShell:
+		return d.runShell(a.Arg)
+	default:
+		return fmt.Errorf("unsupported action")
+	}
+}
+
+// undo deletes the last delivered transcript from the focused application.
+// Only pasted or typed text can be taken back; stdout and tmux output are
+// left alone.
+func (d *dispatcher) undo() error {
+	p := d.p
+	p.mu.Lock()
+	last := p.lastDelivered
+	mode := p.lastMode
+	p.lastDelivered = ""
+	p.mu.Unlock()
+
+	if last == "" {
+		return nil
+	}
+	if mode != "clipboard" && mode != "type" {
+		return fmt.Errorf("undo is not supported for %s output", mode)
+	}
+	return d.deleteBackward(len([]rune(last)))
+}
+
+func (d *dispatcher) switchOutput(mode string) error {
+	cfg := *d.p.cfg
+	cfg.OutputMode = mode
+	out := resolveOutput(&cfg)
+	if out == nil {
+		return fmt.Errorf("unknown output mode: %s", mode)
+	}
+
+	d.p.mu.Lock()
+	d.p.cfg.OutputMode = mode
+	d.p.out = out
+	d.p.mu.Unlock()
+	fmt.Printf("\r\033[KOutput: %s\n", mode)
+	return nil
+}
+
+// startShell runs command through sh without waiting for it to finish.
+func startShell(command string) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
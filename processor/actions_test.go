@@ -0,0 +1,122 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/basilysf1709/golos/internal"
+)
+
+// recorder collects the side effects requested by a dispatcher.
+type recorder struct {
+	chords  []string
+	deleted int
+	shell   []string
+}
+
+func newTestDispatcher(t *testing.T, mode string) (*Processor, *recorder) {
+	t.Helper()
+	cfg := &internal.Config{DeepgramAPIKey: "test-key", OutputMode: mode}
+	p, err := New(cfg, &mockOutput{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	rec := &recorder{}
+	p.actions.sendChord = func(ch internal.Chord) error {
+		rec.chords = append(rec.chords, ch.String())
+		return nil
+	}
+	p.actions.deleteBackward = func(n int) error {
+		rec.deleted += n
+		return nil
+	}
+	p.actions.runShell = func(cmd string) error {
+		rec.shell = append(rec.shell, cmd)
+		return nil
+	}
+	return p, rec
+}
+
+func mustAction(t *testing.T, spec string) internal.Action {
+	t.Helper()
+	a, err := internal.ParseAction(spec)
+	if err != nil {
+		t.Fatalf("ParseAction(%q): %v", spec, err)
+	}
+	return a
+}
+
+func TestDispatcherRunsOnlyMatchingPhase(t *testing.T) {
+	p, rec := newTestDispatcher(t, "clipboard")
+	actions := []internal.Action{
+		mustAction(t, "enter"),
+		mustAction(t, "shell echo hi"),
+		mustAction(t, "key ctrl+c"),
+	}
+
+	p.actions.run(actions, true, p.out)
+	if len(rec.chords) != 0 || len(rec.shell) != 0 {
+		t.Fatalf("before-delivery phase ran after-delivery actions: %+v", rec)
+	}
+
+	p.actions.run(actions, false, p.out)
+	if len(rec.chords) != 2 || rec.chords[0] != "return" || rec.chords[1] != "ctrl+c" {
+		t.Errorf("chords = %v, want [return ctrl+c]", rec.chords)
+	}
+	if len(rec.shell) != 1 || rec.shell[0] != "echo hi" {
+		t.Errorf("shell = %v, want [echo hi]", rec.shell)
+	}
+}
+
+func TestDispatcherUndo(t *testing.T) {
+	p, rec := newTestDispatcher(t, "clipboard")
+	p.lastDelivered = "héllo"
+	p.lastMode = "clipboard"
+
+	p.actions.run([]internal.Action{mustAction(t, "undo")}, true, p.out)
+	if rec.deleted != 5 {
+		t.Errorf("deleted = %d, want 5 (runes, not bytes)", rec.deleted)
+	}
+	if p.lastDelivered != "" {
+		t.Error("lastDelivered should be cleared after undo")
+	}
+
+	// A second undo has nothing left to delete.
+	p.actions.run([]internal.Action{mustAction(t, "undo")}, true, p.out)
+	if rec.deleted != 5 {
+		t.Errorf("deleted = %d after second undo, want 5", rec.deleted)
+	}
+}
+
+func TestDispatcherUndoStdout(t *testing.T) {
+	p, rec := newTestDispatcher(t, "stdout")
+	p.lastDelivered = "hello"
+	p.lastMode = "stdout"
+
+	if err := p.actions.exec(mustAction(t, "undo"), p.out); err == nil {
+		t.Error("expected error undoing stdout output")
+	}
+	if rec.deleted != 0 {
+		t.Errorf("deleted = %d, want 0", rec.deleted)
+	}
+}
+
+func TestDispatcherSwitchOutput(t *testing.T) {
+	p, _ := newTestDispatcher(t, "clipboard")
+
+	if err := p.actions.exec(mustAction(t, "output stdout"), p.out); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if _, ok := p.out.(*internal.StdoutMode); !ok {
+		t.Errorf("out = %T, want *StdoutMode", p.out)
+	}
+	if p.cfg.OutputMode != "stdout" {
+		t.Errorf("OutputMode = %q, want stdout", p.cfg.OutputMode)
+	}
+
+	if err := p.actions.exec(mustAction(t, "output fax"), p.out); err == nil {
+		t.Error("expected error for unknown output mode")
+	}
+	if p.cfg.OutputMode != "stdout" {
+		t.Errorf("OutputMode changed on error: %q", p.cfg.OutputMode)
+	}
+}
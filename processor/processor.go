@@ -3,6 +3,7 @@ package processor
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"strings"
@@ -13,59 +14,238 @@ import (
 )
 
 type Processor struct {
-	cfg        *internal.Config
-	out        internal.OutputMode
-	dict       *internal.Dictionary
-	vad        *internal.Detector
-	mu         sync.Mutex
-	recording  bool
-	capture    *internal.Capture
-	provider   internal.Provider
-	transcript strings.Builder
-	doneCh     chan struct{}
-	gotFinal   chan struct{}
-	connected  chan struct{} // closed when Deepgram connection is ready
-	streamWg   sync.WaitGroup // ensures streamAudio() finishes before Finalize()
+	cfg           *internal.Config
+	rate          int // sample rate sessions are captured and streamed at
+	out           internal.OutputMode
+	dict          *internal.Dictionary
+	pipeline      *internal.Pipeline
+	actions       *dispatcher
+	vad           internal.Detector
+	dsp           *internal.DSP     // nil when no conditioning is configured
+	trim          *internal.Trimmer // nil when silence is streamed as is
+	mu            sync.Mutex
+	recording     bool
+	stopping      bool          // a released session is still being torn down
+	abort         chan struct{} // closed by Cancel while stopping
+	capture       internal.AudioSource
+	standby       *internal.Standby // keeps the mic open with a pre-roll, if configured
+	provider      internal.Provider
+	transcript    strings.Builder
+	languages     []string // language of each final result this session
+	lastDelivered string   // last text handed to the output, for undo
+	lastMode      string   // output mode lastDelivered went to
+	frontmostApp  func() (internal.AppInfo, error)
+	workingDir    func() string
+	dial          func() (internal.Provider, error) // connects to the provider for the current session
+	record        func(internal.HistoryEntry) error // nil when history is off
+	polishMod     *internal.Chord                   // modifiers that request polish, if configured
+	modifiersHeld func(internal.Chord) bool
+	polish        bool             // polish requested for the current session
+	binding       internal.Binding // hotkey binding that started the current session
+	minHold       time.Duration    // shorter presses are dropped
+	startedAt     time.Time
+	held          bool // the hotkey has been held for minHold this session
+	heard         bool // the VAD heard speech this session
+	connecting    bool // connect has been started this session
+	stats         sessionStats
+	audio         *internal.Recording // with record_audio, this session's WAV
+	doneCh        chan struct{}
+	gotFinal      chan struct{}
+	connected     chan struct{}  // closed when Deepgram connection is ready
+	failed        chan struct{}  // closed when connecting to Deepgram failed
+	streamWg      sync.WaitGroup // ensures streamAudio() finishes before Finalize()
 }
 
 func New(cfg *internal.Config, out internal.OutputMode) (*Processor, error) {
-	vad, err := internal.NewDetector(internal.SampleRate, 300, 3)
+	rate := cfg.SampleRate
+	if rate == 0 {
+		rate = internal.SampleRate
+	}
+	if err := internal.CheckSampleRate(rate); err != nil {
+		return nil, err
+	}
+	vad, err := internal.NewVAD(cfg.VAD, rate)
 	if err != nil {
 		return nil, fmt.Errorf("VAD init: %w", err)
 	}
-	return &Processor{cfg: cfg, out: out, vad: vad, dict: internal.LoadDictionary()}, nil
+	dsp, err := internal.NewDSP(cfg.DSP, rate)
+	if err != nil {
+		return nil, err
+	}
+	trim, err := internal.NewTrimmer(cfg.Trim)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := internal.NewPipeline(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p := &Processor{
+		cfg:           cfg,
+		rate:          rate,
+		pipeline:      pipeline,
+		out:           out,
+		vad:           vad,
+		dsp:           dsp,
+		trim:          trim,
+		dict:          internal.LoadDictionary(),
+		frontmostApp:  internal.FrontmostApp,
+		workingDir:    cfg.WorkingDir,
+		modifiersHeld: internal.ModifiersHeld,
+	}
+	p.dial = p.dialDeepgram
+	if cfg.History {
+		p.record = internal.AppendHistory
+	}
+	if cfg.MinHold != "" {
+		d, err := time.ParseDuration(cfg.MinHold)
+		if err != nil {
+			return nil, fmt.Errorf("min_hold: %w", err)
+		}
+		p.minHold = d
+	}
+	if cfg.Polish.Modifier != "" {
+		mod, err := internal.ParseModifiers(cfg.Polish.Modifier)
+		if err != nil {
+			return nil, fmt.Errorf("polish modifier: %w", err)
+		}
+		p.polishMod = &mod
+	}
+	p.actions = newDispatcher(p)
+	return p, nil
+}
+
+// UseStandby makes sessions take their audio from an always-open mic,
+// starting with its pre-roll.
+func (p *Processor) UseStandby(s *internal.Standby) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.standby = s
 }
 
+// standbyPoll is how often WatchStandby checks the overlay.
+var standbyPoll = time.Second
+
+// WatchStandby keeps the always-open mic, if any, open only while visible
+// reports the overlay showing it on screen: it is paused while the
+// overlay is off or hidden, e.g. on a locked screen, and reopened when it
+// is back. It returns when stop is closed.
+func (p *Processor) WatchStandby(visible func() bool, stop <-chan struct{}) {
+	p.mu.Lock()
+	s := p.standby
+	p.mu.Unlock()
+	if s == nil {
+		return
+	}
+	tick := time.NewTicker(standbyPoll)
+	defer tick.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-tick.C:
+		}
+		if !visible() {
+			s.Pause()
+		} else if err := s.Resume(); err != nil {
+			fmt.Fprintf(os.Stderr, "\nMic error: %v\n", err)
+		}
+	}
+}
+
+// Close releases the always-open mic, if any.
+func (p *Processor) Close() {
+	p.mu.Lock()
+	s := p.standby
+	p.standby = nil
+	p.mu.Unlock()
+	if s != nil {
+		s.Close()
+	}
+}
+
+// Dictionary returns the global dictionary the processor matches against.
+func (p *Processor) Dictionary() *internal.Dictionary {
+	return p.dict
+}
+
+// Start begins a session with the global settings.
 func (p *Processor) Start() {
+	p.StartBinding(internal.Binding{Hotkey: p.cfg.Hotkey})
+}
+
+// StartBinding begins a session with the binding's language, output mode
+// and polish setting in place of the global ones.
+func (p *Processor) StartBinding(b internal.Binding) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	if p.recording {
+	if p.recording || p.stopping {
 		return
 	}
 	p.recording = true
+	p.binding = b
+	p.provider = nil
+	p.startedAt = time.Now()
+	p.held, p.heard, p.connecting = false, false, false
+	p.stats = sessionStats{}
 	p.transcript.Reset()
+	p.languages = nil
 	p.vad.Reset()
+	if p.dsp != nil {
+		p.dsp.Reset()
+	}
+	if p.trim != nil {
+		p.trim.Reset()
+	}
+	p.polish = b.Polish || p.polishRequested()
+	p.audio = nil
+	if p.cfg.RecordAudio {
+		rec, err := internal.NewRecording(p.rate, p.language())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nRecording error: %v\n", err)
+		} else {
+			p.audio = rec
+		}
+	}
 
-	fmt.Print("\r\033[K🎙  Listening...")
+	if p.polish {
+		fmt.Print("\r\033[K🎙  Listening (polish)...")
+	} else {
+		fmt.Print("\r\033[K🎙  Listening...")
+	}
 	internal.OverlayShow(0)
 
 	// Start mic immediately — no waiting for network
-	var err error
-	p.capture, err = internal.NewCapture(128)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "\nMic error: %v\n", err)
-		p.recording = false
-		return
-	}
-	if err := p.capture.Start(); err != nil {
-		fmt.Fprintf(os.Stderr, "\nMic start error: %v\n", err)
-		p.recording = false
-		return
+	if p.standby != nil {
+		c, err := p.standby.Begin(128)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nMic error: %v\n", err)
+			p.recording = false
+			p.finishRecording(p.audio, false)
+			return
+		}
+		p.capture = c
+	} else {
+		c, err := internal.NewCapture(128, p.rate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nMic error: %v\n", err)
+			p.recording = false
+			p.finishRecording(p.audio, false)
+			return
+		}
+		if err := c.Start(); err != nil {
+			fmt.Fprintf(os.Stderr, "\nMic start error: %v\n", err)
+			p.recording = false
+			p.finishRecording(p.audio, false)
+			return
+		}
+		p.capture = c
 	}
 
 	p.doneCh = make(chan struct{})
 	p.gotFinal = make(chan struct{})
 	p.connected = make(chan struct{})
+	p.failed = make(chan struct{})
 
 	// Capture session-scoped references so goroutines from a previous
 	// session never touch channels belonging to a new session.
@@ -73,58 +253,156 @@ func (p *Processor) Start() {
 	gotFinal := p.gotFinal
 	conn := p.connected
 
-	// Connect to Deepgram in background
-	go p.connect(conn, done)
+	// Connect to Deepgram in background, once the press is long enough
+	// and, with skip_silence, speech has been heard.
+	if p.minHold > 0 {
+		time.AfterFunc(p.minHold, func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if p.doneCh == done {
+				p.held = true
+				p.connectWhenReady()
+			}
+		})
+	} else {
+		p.held = true
+	}
+	p.connectWhenReady()
 
 	// Audio → STT (buffers until connected)
 	p.streamWg.Add(1)
-	go p.streamAudio(conn, done)
+	go p.streamAudio(conn, done, p.audio)
 
 	// Transcript accumulator
 	go p.accumulate(conn, done, gotFinal)
 }
 
-func (p *Processor) connect(conn chan struct{}, done chan struct{}) {
-	prov := internal.NewDeepgram(p.cfg.DeepgramAPIKey, p.cfg.Language)
+// connectWhenReady starts connecting to the provider for a session that
+// is still recording, has been held for minHold and, with skip_silence,
+// has had speech. Called with p.mu held.
+func (p *Processor) connectWhenReady() {
+	if p.connecting || !p.recording || !p.held || (p.cfg.SkipSilence && !p.heard) {
+		return
+	}
+	p.connecting = true
+	go p.connect(p.connected, p.failed, p.doneCh)
+}
+
+// dialDeepgram connects to Deepgram in the current session's language.
+func (p *Processor) dialDeepgram() (internal.Provider, error) {
+	prov := internal.NewDeepgram(p.cfg.DeepgramAPIKey, p.language(), p.cfg.Languages...)
+	prov.SetSampleRate(p.rate)
 	if err := prov.Connect(); err != nil {
+		return nil, err
+	}
+	return prov, nil
+}
+
+// connect dials the provider for the session whose channels it is given.
+// It only touches that session: a failure closes failed, and a provider
+// connected after the session was discarded is closed again.
+func (p *Processor) connect(conn, failed, done chan struct{}) {
+	prov, err := p.dial()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "\nSTT connect error: %v\n", err)
-		p.mu.Lock()
-		p.recording = false
-		p.mu.Unlock()
+		close(failed)
 		return
 	}
 
 	// If session was cancelled while connecting, discard the provider.
+	// Checked under the lock so Cancel either sees the provider or has
+	// already closed done.
+	p.mu.Lock()
 	select {
 	case <-done:
+		p.mu.Unlock()
 		prov.Close()
 		return
 	default:
 	}
-
-	p.mu.Lock()
 	p.provider = prov
 	p.mu.Unlock()
 	close(conn)
 }
 
+// Stop ends the current session and delivers its transcript.
 func (p *Processor) Stop() {
 	p.mu.Lock()
+	p.stop()
+}
+
+// StopBinding ends the current session if the binding started it, so
+// releasing one hotkey doesn't cut off a session started by another.
+func (p *Processor) StopBinding(b internal.Binding) {
+	p.mu.Lock()
+	if p.binding.Hotkey != b.Hotkey {
+		p.mu.Unlock()
+		return
+	}
+	p.stop()
+}
+
+// stop is called with p.mu held and releases it. The session stays
+// stopping until its transcript is delivered, so a new session can't
+// start on its provider, transcript or recording meanwhile.
+func (p *Processor) stop() {
 	if !p.recording {
 		p.mu.Unlock()
 		return
 	}
 	p.recording = false
+	p.stopping = true
+	abort := make(chan struct{})
+	p.abort = abort
+	internal.SetCancelPending(true)
+	defer p.stopped()
 	internal.OverlayHide()
+	// The modifier may also be pressed after the hotkey.
+	p.polish = p.polish || p.polishRequested()
 
 	cap := p.capture
 	prov := p.provider
+	rec := p.audio
+	p.audio = nil
 	done := p.doneCh
 	gf := p.gotFinal
 	conn := p.connected
+	failed := p.failed
+
+	// Taps and silence never reach the provider.
+	if time.Since(p.startedAt) < p.minHold {
+		prov = p.abandon(done)
+		p.mu.Unlock()
+		p.discard(cap, prov)
+		p.finishRecording(rec, false)
+		fmt.Print("\r\033[K")
+		return
+	}
+	if p.cfg.SkipSilence && !p.heard {
+		prov = p.abandon(done)
+		p.mu.Unlock()
+		p.discard(cap, prov)
+		p.finishRecording(rec, true)
+		fmt.Print("\r\033[K(no speech detected)\n")
+		return
+	}
+	if !p.connecting {
+		p.connecting = true
+		go p.connect(conn, failed, done)
+	}
 	p.mu.Unlock()
 
-	// Wait for connection if still pending (with timeout)
+	// 1. Stop mic — closes the frames channel so streamAudio() drains
+	//    any remaining buffered frames into the spill and exits once the
+	//    spill is sent.
+	if cap != nil {
+		cap.Stop()
+	}
+
+	// 2. Wait for the connection: audio spilled while connecting is only
+	//    sent once it's up. Without one, closing done lets streamAudio
+	//    drop it and finish.
+	aborted := false
 	select {
 	case <-conn:
 		// Connection finished — re-capture provider since it may have been
@@ -132,33 +410,44 @@ func (p *Processor) Stop() {
 		p.mu.Lock()
 		prov = p.provider
 		p.mu.Unlock()
-	case <-time.After(2 * time.Second):
+	case <-failed:
+	case <-time.After(connectWait):
+		fmt.Fprintf(os.Stderr, "\nSTT connect error: not connected after %s\n", connectWait)
+	case <-abort:
+		aborted = true
 	}
-
-	// 1. Stop mic — closes the frames channel so streamAudio() drains
-	//    any remaining buffered frames and exits.
-	if cap != nil {
-		cap.Stop()
+	if prov == nil {
+		p.mu.Lock()
+		prov = p.abandon(done)
+		p.mu.Unlock()
+		done = nil
 	}
 
-	// 2. Wait for streamAudio() to finish sending all frames to Deepgram.
+	// 3. Wait for streamAudio() to finish sending all frames to Deepgram.
 	//    Without this, Finalize() could fire before the last frames are written.
 	p.streamWg.Wait()
+	p.noteGaps(cap)
+	p.noteSpill()
 
-	// 3. Tell Deepgram we're done sending audio.
-	if prov != nil {
+	if prov != nil && !aborted {
+		// 4. Tell Deepgram we're done sending audio.
 		_ = prov.Finalize()
-	}
 
-	// 4. Wait for at least one final result, then drain remaining results.
-	select {
-	case <-gf:
-	case <-time.After(2 * time.Second):
+		// 5. Wait for at least one final result, then drain remaining results.
+		select {
+		case <-gf:
+		case <-abort:
+			aborted = true
+		case <-time.After(2 * time.Second):
+		}
+		if !aborted {
+			// Drain any results still in the channel after the first final.
+			p.drainResults(prov, 300*time.Millisecond)
+		}
 	}
-	// Drain any results still in the channel after the first final.
-	p.drainResults(prov, 300*time.Millisecond)
+	p.finishRecording(rec, true)
 
-	// 5. Now safe to tear down.
+	// 6. Now safe to tear down.
 	if done != nil {
 		close(done)
 	}
@@ -166,19 +455,246 @@ func (p *Processor) Stop() {
 		prov.Close()
 	}
 
+	select {
+	case <-abort:
+		p.cancelled()
+		return
+	default:
+	}
+
 	p.mu.Lock()
 	finalText := p.transcript.String()
 	p.mu.Unlock()
 
 	if finalText != "" {
-		finalText = p.dict.Replace(finalText)
+		p.deliver(finalText)
+	} else {
+		fmt.Print("\r\033[K(no speech detected)\n")
+	}
+}
+
+// connectWait is how long a released session waits for the provider to
+// connect before giving up on its audio.
+var connectWait = 30 * time.Second
+
+// stopped ends the stopping state, letting the next session start.
+func (p *Processor) stopped() {
+	p.mu.Lock()
+	p.stopping = false
+	p.abort = nil
+	p.mu.Unlock()
+	internal.SetCancelPending(false)
+}
+
+// Cancel ends the current session without delivering anything: the mic
+// and the provider are torn down and whatever was heard is only recorded
+// in the history, marked as cancelled. A released session still waiting
+// for its transcript is cancelled too.
+func (p *Processor) Cancel() {
+	p.mu.Lock()
+	if p.stopping {
+		if p.abort != nil {
+			close(p.abort)
+			p.abort = nil
+		}
+		p.mu.Unlock()
+		internal.OverlayCancel()
+		return
+	}
+	if !p.recording {
+		p.mu.Unlock()
+		return
+	}
+	p.recording = false
+	cap := p.capture
+	rec := p.audio
+	p.audio = nil
+	prov := p.abandon(p.doneCh)
+	p.mu.Unlock()
+
+	internal.OverlayCancel()
+	p.discard(cap, prov)
+	p.finishRecording(rec, true)
+	p.cancelled()
+}
+
+// cancelled reports a cancelled session and records what was heard in
+// the history.
+func (p *Processor) cancelled() {
+	fmt.Print("\r\033[K(cancelled)\n")
+
+	p.mu.Lock()
+	heard := p.transcript.String()
+	p.mu.Unlock()
+	if p.record != nil {
+		lang := p.sessionLanguage()
+		entry := internal.HistoryEntry{Time: time.Now(), Raw: heard, Language: lang, Unlisted: p.unlisted(lang), Cancelled: true}
+		if err := p.record(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "History error: %v\n", err)
+		}
+	}
+}
+
+// abandon tells the session's goroutines to stop and returns its
+// provider, if connected. Closing done drops the audio waiting for the
+// provider, and since connect checks done under the lock too, a provider
+// connected later is closed by connect itself. Called with p.mu held.
+func (p *Processor) abandon(done chan struct{}) internal.Provider {
+	if done != nil {
+		close(done)
+	}
+	return p.provider
+}
+
+// discard tears down a session abandoned without finalizing it: the mic
+// is stopped and its provider, if connected, is closed.
+func (p *Processor) discard(cap internal.AudioSource, prov internal.Provider) {
+	if cap != nil {
+		cap.Stop()
+	}
+	p.streamWg.Wait()
+	if prov != nil {
+		prov.Close()
+	}
+}
+
+// finishRecording closes a session's recording, if any, keeping it or
+// removing it. It doesn't take p.mu, so it may be called with it held.
+func (p *Processor) finishRecording(rec *internal.Recording, keep bool) {
+	if rec == nil {
+		return
+	}
+	if !keep {
+		rec.Discard()
+		return
+	}
+	if err := rec.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Recording error: %v\n", err)
+	}
+}
+
+// deliver runs the transcript through voice commands, the submit trigger
+// and the post-processing pipeline, then hands it to the output. The
+// dictionary and output mode come from the frontmost application's
+// profile, if one applies.
+func (p *Processor) deliver(text string) {
+	raw := text
+	lang := p.sessionLanguage()
+	app, name, prof := p.activeProfile()
+	dir := p.workingDir()
+	dict := p.sessionDict(dir, name, prof)
+
+	// Voice commands are pulled out first so their phrases never reach
+	// the pipeline; whatever text remains is still delivered.
+	text, actions := dict.ExtractCommands(text)
+	p.actions.run(actions, true, p.out)
+
+	// Resolved after the commands so an output switch takes effect now.
+	out, mode := p.sessionOutput(prof)
+
+	text, submit := internal.StripTrigger(text, p.cfg.SubmitTriggers)
+
+	fmt.Print("\r\033[K")
+	delivered := ""
+	if text != "" {
+		p.mu.Lock()
+		polish := p.polish
+		p.mu.Unlock()
+		if polish {
+			fmt.Print("✨ Polishing...")
+		}
+		session := internal.Session{App: app, Profile: name, Output: mode, Dir: dir, Language: lang, Dict: dict, Polish: polish}
+		var err error
+		text, err = p.pipeline.Run(text, session)
 		fmt.Print("\r\033[K")
-		if err := p.out.Deliver(finalText); err != nil {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Pipeline error: %v\n", err)
+		}
+	}
+	if text != "" {
+		if err := p.deliverText(out, text, prof); err != nil {
 			fmt.Fprintf(os.Stderr, "Output error: %v\n", err)
+			submit = false
+		} else {
+			delivered = text
+			p.mu.Lock()
+			p.lastDelivered = text
+			p.lastMode = mode
+			p.mu.Unlock()
 		}
-		fmt.Print("\r\033[K")
-	} else {
-		fmt.Print("\r\033[K(no speech detected)\n")
+	}
+	if submit {
+		p.submit(out, mode)
+	}
+	p.actions.run(actions, false, out)
+	fmt.Print("\r\033[K")
+
+	if p.record != nil {
+		p.mu.Lock()
+		st := p.stats
+		p.mu.Unlock()
+		entry := internal.HistoryEntry{Time: time.Now(), Raw: raw, Delivered: delivered, Output: mode, Profile: name, Language: lang, Unlisted: p.unlisted(lang),
+			Dropped: st.Dropped, Gaps: st.Gaps, Spilled: st.Spilled, OnDisk: st.OnDisk}
+		if err := p.record(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "History error: %v\n", err)
+		}
+	}
+}
+
+// language returns the language set for this session: the binding's if
+// it has one, otherwise the configured one.
+func (p *Processor) language() string {
+	if p.binding.Language != "" {
+		return p.binding.Language
+	}
+	return p.cfg.Language
+}
+
+// sessionLanguage returns the language most of this session was spoken
+// in: detected when the language is auto, otherwise the configured one.
+// It is empty when nothing was detected.
+func (p *Processor) sessionLanguage() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if lang := p.language(); !internal.IsAutoLanguage(lang) {
+		return lang
+	}
+	return internal.DominantLanguage(p.languages)
+}
+
+// unlisted reports whether lang was detected but isn't one of the
+// configured languages.
+func (p *Processor) unlisted(lang string) bool {
+	return internal.IsAutoLanguage(p.language()) && internal.Unlisted(lang, p.cfg.Languages)
+}
+
+// languageTag prefixes the status line with the detected language when
+// the language is auto, marked with "?" when it isn't in languages.
+func (p *Processor) languageTag(result internal.TranscriptResult) string {
+	if !internal.IsAutoLanguage(p.language()) || result.Language == "" {
+		return ""
+	}
+	if p.unlisted(result.Language) {
+		return "[" + result.Language + "?] "
+	}
+	return "[" + result.Language + "] "
+}
+
+// polishRequested reports whether the polish modifier is held.
+func (p *Processor) polishRequested() bool {
+	return p.polishMod != nil && p.modifiersHeld(*p.polishMod)
+}
+
+// submit asks the output mode to submit what was just delivered. Modes
+// without a Submitter (stdout) ignore the trigger.
+func (p *Processor) submit(out internal.OutputMode, mode string) {
+	s, ok := out.(internal.Submitter)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Output mode %s cannot submit\n", mode)
+		return
+	}
+	if err := s.Submit(); err != nil {
+		fmt.Fprintf(os.Stderr, "Submit error: %v\n", err)
 	}
 }
 
@@ -195,13 +711,7 @@ func (p *Processor) drainResults(prov internal.Provider, timeout time.Duration)
 				return
 			}
 			p.mu.Lock()
-			if result.IsFinal {
-				if p.transcript.Len() > 0 {
-					p.transcript.WriteString(" ")
-				}
-				p.transcript.WriteString(result.Text)
-				fmt.Printf("\r\033[K💬 %s", p.transcript.String())
-			}
+			p.takeResult(result)
 			p.mu.Unlock()
 		case <-time.After(timeout):
 			return
@@ -209,55 +719,178 @@ func (p *Processor) drainResults(prov internal.Provider, timeout time.Duration)
 	}
 }
 
-func (p *Processor) streamAudio(conn chan struct{}, done chan struct{}) {
+func (p *Processor) streamAudio(conn chan struct{}, done chan struct{}, rec *internal.Recording) {
 	defer p.streamWg.Done()
 
-	// Buffer up to 30 seconds of audio while Deepgram connects.
-	// At 16 kHz with 128-sample frames, that's ~3750 frames.
-	const maxBuffered = 3750
-
-	var buffered [][]byte
-	var prov internal.Provider
+	// Audio waits in the spill while Deepgram connects or falls behind,
+	// so reading the mic never blocks on the network and none is dropped.
+	spill := internal.NewSpill(int(spillMemory.Seconds()*float64(p.rate)) * 2)
+	defer spill.Release()
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		p.sendAudio(spill, conn, done)
+	}()
 
 	for frame := range p.capture.Frames() {
-		_, _ = p.vad.Process(frame)
+		// Recordings keep the mic's audio as it was, so a replay can try
+		// other DSP settings on it.
+		if rec != nil {
+			if err := rec.Write(frame); err != nil {
+				fmt.Fprintf(os.Stderr, "\nRecording error: %v\n", err)
+				rec = nil
+			}
+		}
+		if p.dsp != nil {
+			frame = p.dsp.Process(frame)
+		}
+		ev := p.detectSpeech(frame)
 
 		level := rmsLevel(frame)
 		meter := vuMeter(level)
-		fmt.Printf("\r\033[K🎙  Listening %s", meter)
+		fmt.Printf("\r\033[K🎙  Listening %s%s", meter, backlog(spill.Waiting(), p.rate))
 
-		buf := make([]byte, len(frame)*2)
-		for i, s := range frame {
-			binary.LittleEndian.PutUint16(buf[i*2:], uint16(s))
+		for _, f := range p.trimmed(frame, ev) {
+			if err := spill.Push(pcmBytes(f)); err != nil {
+				fmt.Fprintf(os.Stderr, "\nAudio spill error: %v\n", err)
+			}
 		}
+	}
+	spill.Close()
+	// Nothing more is pushed, so the peak is final.
+	peak, onDisk := spill.Peak()
+	p.mu.Lock()
+	p.stats.Spilled, p.stats.OnDisk = peak, onDisk
+	p.mu.Unlock()
+	<-sent
+}
 
-		select {
-		case <-conn:
-			// Capture provider once after connection is ready
-			if prov == nil {
-				p.mu.Lock()
-				prov = p.provider
-				p.mu.Unlock()
-			}
-			if prov == nil {
-				continue
-			}
-			// Connected — flush buffer then stream normally
-			if len(buffered) > 0 {
-				for _, b := range buffered {
-					_, _ = prov.Write(b)
-				}
-				buffered = nil
-			}
-			_, _ = prov.Write(buf)
-		default:
-			// Still connecting — buffer the audio, drop oldest if over limit
-			if len(buffered) >= maxBuffered {
-				buffered = buffered[1:]
+// pcmBytes encodes samples as little-endian PCM16.
+func pcmBytes(samples []int16) []byte {
+	buf := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(buf[i*2:], uint16(s))
+	}
+	return buf
+}
+
+// spillMemory is how much audio the spill keeps in memory before it
+// moves to a temp file.
+var spillMemory = 30 * time.Second
+
+// backlog describes the audio waiting for the provider for the status
+// line, or returns "" when there's less than a second of it.
+func backlog(waiting, rate int) string {
+	secs := float64(waiting) / float64(rate*2)
+	if secs < 1 {
+		return ""
+	}
+	return fmt.Sprintf(" (%.0fs waiting for Deepgram)", secs)
+}
+
+// sendAudio writes the spilled audio to the provider once it's
+// connected, until the mic has stopped and the spill is drained. It
+// waits for the connection even after the mic stops, and gives up only
+// when the session is discarded.
+func (p *Processor) sendAudio(spill *internal.Spill, conn, done chan struct{}) {
+	select {
+	case <-conn:
+	case <-done:
+		return
+	}
+	p.mu.Lock()
+	prov := p.provider
+	p.mu.Unlock()
+	if prov == nil {
+		return
+	}
+	for {
+		chunk, err := spill.Next()
+		if err != nil {
+			if err != io.EOF {
+				fmt.Fprintf(os.Stderr, "\nAudio spill error: %v\n", err)
 			}
-			buffered = append(buffered, buf)
+			return
 		}
+		_, _ = prov.Write(chunk)
+	}
+}
+
+// sessionStats records what happened to a session's audio on its way to
+// the provider.
+type sessionStats struct {
+	Dropped int  // frames the mic dropped because streamAudio fell behind
+	Gaps    int  // runs of dropped frames
+	Spilled int  // most bytes waiting for the provider at once
+	OnDisk  bool // the spill overflowed to a temp file
+}
+
+// noteGaps adds the frames the audio source dropped to the session stats
+// and warns about them, since they leave holes in the transcript.
+func (p *Processor) noteGaps(src internal.AudioSource) {
+	if src == nil {
+		return
+	}
+	gaps := src.Gaps()
+	dropped := 0
+	for _, g := range gaps {
+		dropped += g.Frames
+	}
+	p.mu.Lock()
+	p.stats.Dropped, p.stats.Gaps = dropped, len(gaps)
+	p.mu.Unlock()
+	if dropped > 0 {
+		fmt.Fprintf(os.Stderr, "\r\033[KAudio: %d frames (%dms) dropped in %d gaps, first at %dms\n",
+			dropped, dropped*internal.FrameDurMs, len(gaps), gaps[0].At*internal.FrameDurMs)
+	}
+}
+
+// noteSpill tells the user when audio had to wait a second or more for
+// the provider, since the transcript is late by as much.
+func (p *Processor) noteSpill() {
+	p.mu.Lock()
+	st := p.stats
+	p.mu.Unlock()
+	if st.Spilled < p.rate*2 && !st.OnDisk {
+		return
 	}
+	where := "in memory"
+	if st.OnDisk {
+		where = "on disk"
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[KAudio: up to %.1fs waited for Deepgram %s\n",
+		float64(st.Spilled)/float64(p.rate*2), where)
+}
+
+// minVoiced is how many voiced 20ms frames in a row count as speech, so
+// a click or a breath doesn't.
+const minVoiced = 5
+
+// detectSpeech runs the VAD over a frame and notes when the session has
+// had speech, which lets a skip_silence session connect. It returns the
+// VAD's event for the frame.
+func (p *Processor) detectSpeech(frame []int16) internal.VADEvent {
+	ev, _ := p.vad.Process(frame)
+	if p.vad.Voiced() < minVoiced {
+		return ev
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.heard {
+		p.heard = true
+		p.connectWhenReady()
+	}
+	return ev
+}
+
+// trimmed returns the frames to stream for a frame the VAD returned ev
+// for: the frame itself, unless silence trimming holds it back or
+// releases held frames with it.
+func (p *Processor) trimmed(frame []int16, ev internal.VADEvent) [][]int16 {
+	if p.trim == nil {
+		return [][]int16{frame}
+	}
+	return p.trim.Push(frame, ev)
 }
 
 func (p *Processor) accumulate(conn chan struct{}, done chan struct{}, gotFinal chan struct{}) {
@@ -278,28 +911,39 @@ func (p *Processor) accumulate(conn chan struct{}, done chan struct{}, gotFinal
 				return
 			}
 			p.mu.Lock()
-			if result.IsFinal {
-				if p.transcript.Len() > 0 {
-					p.transcript.WriteString(" ")
-				}
-				p.transcript.WriteString(result.Text)
-				fmt.Printf("\r\033[K💬 %s", p.transcript.String())
-				if !finalSignaled {
-					finalSignaled = true
-					close(gotFinal)
-				}
-			} else {
-				interim := p.transcript.String()
-				if interim != "" {
-					interim += " "
-				}
-				fmt.Printf("\r\033[K💬 %s%s", interim, result.Text)
+			p.takeResult(result)
+			if result.IsFinal && !finalSignaled {
+				finalSignaled = true
+				close(gotFinal)
 			}
 			p.mu.Unlock()
 		}
 	}
 }
 
+// takeResult adds a final result to the transcript and shows the
+// transcript so far, with an interim result on the end. Called with p.mu
+// held.
+func (p *Processor) takeResult(result internal.TranscriptResult) {
+	if p.audio != nil {
+		p.audio.AddResult(result)
+	}
+	if !result.IsFinal {
+		interim := p.transcript.String()
+		if interim != "" {
+			interim += " "
+		}
+		fmt.Printf("\r\033[K💬 %s%s%s", p.languageTag(result), interim, result.Text)
+		return
+	}
+	if p.transcript.Len() > 0 {
+		p.transcript.WriteString(" ")
+	}
+	p.transcript.WriteString(result.Text)
+	p.languages = append(p.languages, result.Language)
+	fmt.Printf("\r\033[K💬 %s%s", p.languageTag(result), p.transcript.String())
+}
+
 func rmsLevel(frame []int16) float64 {
 	var sum float64
 	for _, s := range frame {
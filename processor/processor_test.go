@@ -1,7 +1,10 @@
 package processor
 
 import (
+	"errors"
 	"math"
+	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -44,6 +47,12 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewRejectsSampleRate(t *testing.T) {
+	if _, err := New(&internal.Config{DeepgramAPIKey: "test-key", SampleRate: 44100}, &mockOutput{}); err == nil {
+		t.Error("expected error for a rate the VAD can't handle")
+	}
+}
+
 func TestStopWhenNotRecording(t *testing.T) {
 	cfg := &internal.Config{
 		DeepgramAPIKey: "test-key",
@@ -64,6 +73,274 @@ func TestStopWhenNotRecording(t *testing.T) {
 	}
 }
 
+func TestStopBindingIgnoresOtherHotkeys(t *testing.T) {
+	p, out := newProfileProcessor(t, internal.AppInfo{}, nil)
+	p.recording = true
+	p.binding = internal.Binding{Hotkey: "f19"}
+
+	p.StopBinding(internal.Binding{Hotkey: "right_option"})
+	if !p.recording || out.delivered != "" {
+		t.Error("releasing another hotkey should not stop the session")
+	}
+}
+
+func TestCancelDiscardsSession(t *testing.T) {
+	p, out := newProfileProcessor(t, internal.AppInfo{}, nil)
+	var entries []internal.HistoryEntry
+	p.record = func(e internal.HistoryEntry) error {
+		entries = append(entries, e)
+		return nil
+	}
+	p.recording = true
+	p.doneCh = make(chan struct{})
+	p.transcript.WriteString("scratch that")
+
+	p.Cancel()
+	if p.recording {
+		t.Error("still recording after cancel")
+	}
+	select {
+	case <-p.doneCh:
+	default:
+		t.Error("session goroutines were not told to stop")
+	}
+	if out.delivered != "" {
+		t.Errorf("delivered %q after cancel", out.delivered)
+	}
+	if len(entries) != 1 || !entries[0].Cancelled || entries[0].Raw != "scratch that" || entries[0].Delivered != "" {
+		t.Errorf("history = %+v, want one cancelled entry", entries)
+	}
+
+	p.Cancel() // not recording: nothing to do
+	if len(entries) != 1 {
+		t.Errorf("second cancel recorded again: %+v", entries)
+	}
+}
+
+func TestStopDropsTaps(t *testing.T) {
+	p, out := newProfileProcessor(t, internal.AppInfo{}, nil)
+	p.recording = true
+	p.doneCh = make(chan struct{})
+	p.startedAt = time.Now()
+	p.minHold = time.Hour
+	p.heard = true
+
+	p.Stop()
+	if p.connecting || out.delivered != "" {
+		t.Errorf("tap connected=%v delivered=%q, want neither", p.connecting, out.delivered)
+	}
+	select {
+	case <-p.doneCh:
+	default:
+		t.Error("session goroutines were not told to stop")
+	}
+}
+
+func TestStopDiscardsTapRecording(t *testing.T) {
+	p, _ := newProfileProcessor(t, internal.AppInfo{}, nil)
+	p.recording = true
+	p.doneCh = make(chan struct{})
+	p.startedAt = time.Now()
+	p.minHold = time.Hour
+	rec, err := internal.NewRecording(p.rate, "")
+	if err != nil {
+		t.Fatalf("NewRecording: %v", err)
+	}
+	p.audio = rec
+
+	p.Stop()
+	if _, err := os.Stat(rec.Path()); !os.IsNotExist(err) {
+		t.Error("a tap's recording was kept")
+	}
+}
+
+func TestStopSkipsSilence(t *testing.T) {
+	p, out := newProfileProcessor(t, internal.AppInfo{}, nil)
+	p.cfg.SkipSilence = true
+	p.recording = true
+	p.doneCh = make(chan struct{})
+	p.startedAt = time.Now().Add(-time.Second)
+	p.minHold = 300 * time.Millisecond
+
+	p.Stop()
+	if p.connecting || out.delivered != "" {
+		t.Errorf("silent session connected=%v delivered=%q, want neither", p.connecting, out.delivered)
+	}
+}
+
+// closedProvider is a mock provider that reports when it is closed.
+type closedProvider struct {
+	mockProvider
+	closed chan struct{}
+}
+
+func (c *closedProvider) Close() { close(c.closed) }
+
+// startConnecting puts p in a recording session whose connect is waiting
+// on dial, and returns the session's failed channel.
+func startConnecting(p *Processor, dial func() (internal.Provider, error)) chan struct{} {
+	p.dial = dial
+	p.recording = true
+	p.doneCh, p.connected, p.failed = make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go p.connect(p.connected, p.failed, p.doneCh)
+	return p.failed
+}
+
+// nextSession starts another session the way StartBinding resets it.
+func nextSession(p *Processor) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recording = true
+	p.provider = nil
+	p.doneCh = make(chan struct{})
+}
+
+func TestCancelDuringFailingConnect(t *testing.T) {
+	p, _ := newProfileProcessor(t, internal.AppInfo{}, nil)
+	dialed := make(chan error)
+	failed := startConnecting(p, func() (internal.Provider, error) { return nil, <-dialed })
+
+	p.Cancel()
+	nextSession(p)
+	dialed <- errors.New("timeout")
+	<-failed
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.recording {
+		t.Error("a cancelled session's failed connect stopped the next session")
+	}
+}
+
+func TestCancelDuringSlowConnect(t *testing.T) {
+	p, _ := newProfileProcessor(t, internal.AppInfo{}, nil)
+	prov := &closedProvider{closed: make(chan struct{})}
+	dialed := make(chan internal.Provider)
+	startConnecting(p, func() (internal.Provider, error) { return <-dialed, nil })
+
+	p.Cancel()
+	nextSession(p)
+	dialed <- prov
+	select {
+	case <-prov.closed:
+	case <-time.After(time.Second):
+		t.Fatal("the provider connected for a cancelled session was left open")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.provider != nil {
+		t.Error("the cancelled session's provider leaked into the next session")
+	}
+}
+
+// stoppedSource is a fakeSource that reports when it is stopped.
+type stoppedSource struct {
+	fakeSource
+	stopped chan struct{}
+}
+
+func (s *stoppedSource) Stop() {
+	s.fakeSource.Stop()
+	close(s.stopped)
+}
+
+// releaseConnecting stops a session whose connect is waiting on dial in
+// the background, and returns its mic and a channel closed once Stop
+// returns.
+func releaseConnecting(p *Processor, dial func() (internal.Provider, error)) (*stoppedSource, chan struct{}) {
+	src := &stoppedSource{fakeSource: fakeSource{frames: make(chan []int16)}, stopped: make(chan struct{})}
+	startConnecting(p, dial)
+	p.connecting = true
+	p.capture = src
+	p.gotFinal = make(chan struct{})
+	p.streamWg.Add(1)
+	go p.streamAudio(p.connected, p.doneCh, nil)
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		p.Stop()
+	}()
+	return src, stopped
+}
+
+func TestStopWhileConnecting(t *testing.T) {
+	p, out := newProfileProcessor(t, internal.AppInfo{}, nil)
+	var entries []internal.HistoryEntry
+	p.record = func(e internal.HistoryEntry) error {
+		entries = append(entries, e)
+		return nil
+	}
+	prov := &closedProvider{closed: make(chan struct{})}
+	dialed := make(chan internal.Provider)
+	src, stopped := releaseConnecting(p, func() (internal.Provider, error) { return <-dialed, nil })
+
+	select {
+	case <-src.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("the mic kept recording while waiting for the connection")
+	}
+
+	p.StartBinding(internal.Binding{})
+	p.mu.Lock()
+	recording := p.recording
+	p.mu.Unlock()
+	if recording {
+		t.Error("a new session started while the last one was stopping")
+	}
+
+	p.Cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("cancel didn't end the wait for the connection")
+	}
+	if out.delivered != "" {
+		t.Errorf("delivered %q after cancel", out.delivered)
+	}
+	if len(entries) != 1 || !entries[0].Cancelled {
+		t.Errorf("history = %+v, want one cancelled entry", entries)
+	}
+
+	dialed <- prov
+	select {
+	case <-prov.closed:
+	case <-time.After(time.Second):
+		t.Fatal("the provider connected for a cancelled session was left open")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopping {
+		t.Error("still stopping after the session was torn down")
+	}
+}
+
+func TestStopGivesUpConnecting(t *testing.T) {
+	old := connectWait
+	connectWait = 50 * time.Millisecond
+	defer func() { connectWait = old }()
+
+	p, out := newProfileProcessor(t, internal.AppInfo{}, nil)
+	dialed := make(chan error)
+	_, stopped := releaseConnecting(p, func() (internal.Provider, error) { return nil, <-dialed })
+	defer func() { dialed <- errors.New("timeout") }()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("stop waited past connectWait")
+	}
+	if out.delivered != "" {
+		t.Errorf("delivered %q without a connection", out.delivered)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopping {
+		t.Error("still stopping after giving up on the connection")
+	}
+}
+
 func TestRmsLevelSilence(t *testing.T) {
 	frame := make([]int16, 320)
 	level := rmsLevel(frame)
@@ -250,6 +527,40 @@ func TestAccumulateMultipleFinals(t *testing.T) {
 	}
 }
 
+// mockSubmitter is an output that records Submit calls.
+type mockSubmitter struct {
+	mockOutput
+	submitted int
+}
+
+func (m *mockSubmitter) Submit() error {
+	m.submitted++
+	return nil
+}
+
+func TestSubmitUsesSubmitter(t *testing.T) {
+	cfg := &internal.Config{DeepgramAPIKey: "test-key"}
+	out := &mockSubmitter{}
+	p, err := New(cfg, out)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	p.submit(out, "mock")
+	if out.submitted != 1 {
+		t.Errorf("submitted = %d, want 1", out.submitted)
+	}
+}
+
+func TestSubmitWithoutSubmitter(t *testing.T) {
+	cfg := &internal.Config{DeepgramAPIKey: "test-key", OutputMode: "stdout"}
+	p, err := New(cfg, &mockOutput{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	// Should not panic
+	p.submit(&mockOutput{}, "stdout")
+}
+
 // mockProvider implements internal.Provider for testing.
 type mockProvider struct {
 	results chan internal.TranscriptResult
@@ -260,3 +571,230 @@ func (m *mockProvider) Write(p []byte) (int, error)             { return len(p),
 func (m *mockProvider) Results() <-chan internal.TranscriptResult { return m.results }
 func (m *mockProvider) Finalize() error                         { return nil }
 func (m *mockProvider) Close()                                  {}
+
+func TestDrainResultsRecordsLanguage(t *testing.T) {
+	cfg := &internal.Config{DeepgramAPIKey: "test-key", Language: "auto", Languages: []string{"en", "es"}}
+	p, err := New(cfg, &mockOutput{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got := p.sessionLanguage(); got != "" {
+		t.Errorf("sessionLanguage before results = %q, want none detected", got)
+	}
+
+	results := make(chan internal.TranscriptResult, 8)
+	results <- internal.TranscriptResult{Text: "hola", IsFinal: true, Language: "es"}
+	results <- internal.TranscriptResult{Text: "qué tal", IsFinal: true, Language: "es"}
+	results <- internal.TranscriptResult{Text: "okay", IsFinal: true, Language: "en"}
+	close(results)
+	p.drainResults(&mockProvider{results: results}, 100*time.Millisecond)
+
+	if got := p.sessionLanguage(); got != "es" {
+		t.Errorf("sessionLanguage = %q, want es", got)
+	}
+	if got := p.languageTag(internal.TranscriptResult{Language: "es"}); got != "[es] " {
+		t.Errorf("languageTag = %q", got)
+	}
+	if got := p.languageTag(internal.TranscriptResult{Language: "fr"}); got != "[fr?] " {
+		t.Errorf("unlisted languageTag = %q, want it marked", got)
+	}
+
+	p.cfg.Language = "de-DE"
+	if got := p.sessionLanguage(); got != "de-DE" {
+		t.Errorf("fixed sessionLanguage = %q, want the configured language", got)
+	}
+	if got := p.languageTag(internal.TranscriptResult{Language: "de-DE"}); got != "" {
+		t.Errorf("fixed languageTag = %q, want none", got)
+	}
+}
+
+// fakeSource is an AudioSource fed by the test.
+type fakeSource struct {
+	frames chan []int16
+	gaps   []internal.Gap
+}
+
+func (f *fakeSource) Frames() <-chan []int16 { return f.frames }
+func (f *fakeSource) Stop()                  { close(f.frames) }
+func (f *fakeSource) Gaps() []internal.Gap   { return f.gaps }
+
+// stalledProvider blocks every Write until released.
+type stalledProvider struct {
+	mockProvider
+	release chan struct{}
+	mu      sync.Mutex
+	written []byte
+}
+
+func (s *stalledProvider) Write(p []byte) (int, error) {
+	<-s.release
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.written = append(s.written, p...)
+	return len(p), nil
+}
+
+func TestStreamAudioSurvivesStalledProvider(t *testing.T) {
+	old := spillMemory
+	spillMemory = 10 * internal.FrameDurMs * time.Millisecond
+	defer func() { spillMemory = old }()
+
+	p, err := New(&internal.Config{DeepgramAPIKey: "test-key"}, &mockOutput{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	src := &fakeSource{frames: make(chan []int16)}
+	prov := &stalledProvider{release: make(chan struct{})}
+	p.capture = src
+	p.provider = prov
+	conn := make(chan struct{})
+	close(conn)
+	p.streamWg.Add(1)
+	go p.streamAudio(conn, make(chan struct{}), nil)
+
+	// The mic must keep being read while the provider is stuck.
+	const n = 100
+	for i := range n {
+		frame := make([]int16, internal.FrameSamples)
+		for j := range frame {
+			frame[j] = int16(i)
+		}
+		select {
+		case src.frames <- frame:
+		case <-time.After(time.Second):
+			t.Fatalf("streamAudio stopped reading the mic at frame %d", i)
+		}
+	}
+	// Release the provider only once every frame is in the spill.
+	src.Stop()
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		p.mu.Lock()
+		spilled := p.stats.Spilled
+		p.mu.Unlock()
+		if spilled > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("streamAudio never closed the spill")
+		}
+	}
+	close(prov.release)
+	p.streamWg.Wait()
+
+	if len(prov.written) != n*internal.FrameSamples*2 {
+		t.Fatalf("wrote %d bytes, want %d", len(prov.written), n*internal.FrameSamples*2)
+	}
+	for i := range n {
+		if got := prov.written[i*internal.FrameSamples*2]; got != byte(i) {
+			t.Fatalf("frame %d out of order: got %d", i, got)
+		}
+	}
+	if !p.stats.OnDisk || p.stats.Spilled < (n-1)*internal.FrameSamples*2 {
+		t.Errorf("stats = %+v, want most of the audio spilled to disk", p.stats)
+	}
+}
+
+func TestStreamAudioWaitsForSlowConnect(t *testing.T) {
+	p, err := New(&internal.Config{DeepgramAPIKey: "test-key"}, &mockOutput{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	src := &fakeSource{frames: make(chan []int16, 10)}
+	prov := &stalledProvider{release: make(chan struct{})}
+	close(prov.release)
+	p.capture = src
+	conn := make(chan struct{})
+	for range 10 {
+		src.frames <- make([]int16, internal.FrameSamples)
+	}
+	// The mic stops before the connection is up.
+	src.Stop()
+	p.streamWg.Add(1)
+	go p.streamAudio(conn, make(chan struct{}), nil)
+
+	time.Sleep(50 * time.Millisecond)
+	p.mu.Lock()
+	p.provider = prov
+	p.mu.Unlock()
+	close(conn)
+	p.streamWg.Wait()
+
+	if len(prov.written) != 10*internal.FrameSamples*2 {
+		t.Errorf("wrote %d bytes, want %d", len(prov.written), 10*internal.FrameSamples*2)
+	}
+}
+
+func TestStreamAudioDropsDiscardedSession(t *testing.T) {
+	p, err := New(&internal.Config{DeepgramAPIKey: "test-key"}, &mockOutput{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	src := &fakeSource{frames: make(chan []int16, 1)}
+	src.frames <- make([]int16, internal.FrameSamples)
+	src.Stop()
+	p.capture = src
+	done := make(chan struct{})
+	close(done)
+	p.streamWg.Add(1)
+	finished := make(chan struct{})
+	go func() {
+		p.streamAudio(make(chan struct{}), done, nil)
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("streamAudio kept waiting for a discarded session's connection")
+	}
+}
+
+func TestNoteGaps(t *testing.T) {
+	p, err := New(&internal.Config{DeepgramAPIKey: "test-key"}, &mockOutput{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	p.noteGaps(&fakeSource{gaps: []internal.Gap{{At: 10, Frames: 3}, {At: 50, Frames: 2}}})
+	if p.stats.Dropped != 5 || p.stats.Gaps != 2 {
+		t.Errorf("stats = %+v, want 5 frames dropped in 2 gaps", p.stats)
+	}
+}
+
+func TestStreamAudioTrimsSilence(t *testing.T) {
+	p, err := New(&internal.Config{DeepgramAPIKey: "test-key", Trim: internal.TrimConfig{Enabled: true, Pad: "200ms"}}, &mockOutput{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	src := &fakeSource{frames: make(chan []int16, 200)}
+	prov := &stalledProvider{release: make(chan struct{})}
+	close(prov.release)
+	p.capture = src
+	p.provider = prov
+	conn := make(chan struct{})
+	close(conn)
+
+	const speech = 30
+	for range 50 {
+		src.frames <- make([]int16, internal.FrameSamples)
+	}
+	for i := range speech {
+		frame := make([]int16, internal.FrameSamples)
+		for j := range frame {
+			n := i*internal.FrameSamples + j
+			frame[j] = int16(20000 * math.Sin(2*math.Pi*400*float64(n)/internal.SampleRate))
+		}
+		src.frames <- frame
+	}
+	for range 100 {
+		src.frames <- make([]int16, internal.FrameSamples)
+	}
+	src.Stop()
+	p.streamWg.Add(1)
+	p.streamAudio(conn, make(chan struct{}), nil)
+
+	// Speech, 10 frames of pad and the 15-frame VAD hangover, give or
+	// take the VAD's own smoothing.
+	sent := len(prov.written) / (internal.FrameSamples * 2)
+	if sent < speech+10 || sent > speech+10+15+10 {
+		t.Errorf("sent %d of %d frames, want about %d", sent, 180, speech+25)
+	}
+}
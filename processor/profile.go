@@ -0,0 +1,94 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/basilysf1709/golos/internal"
+)
+
+// activeProfile returns the frontmost application and the profile
+// configured for it, or nil when none applies or the app cannot be
+// detected. Without profiles the app is not looked up.
+func (p *Processor) activeProfile() (internal.AppInfo, string, *internal.Profile) {
+	if len(p.cfg.Profiles) == 0 {
+		return internal.AppInfo{}, "", nil
+	}
+	app, err := p.frontmostApp()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\nProfile: %v\n", err)
+		return app, "", nil
+	}
+	name, prof := p.cfg.ProfileFor(app)
+	return app, name, prof
+}
+
+// sessionDict returns the dictionary for this session. Layers are applied
+// from least to most specific: the global dictionary, the project
+// dictionary found from the working directory, then the profile's words
+// and commands.
+func (p *Processor) sessionDict(dir, name string, prof *internal.Profile) *internal.Dictionary {
+	dict, _ := p.dict.WithProject(dir)
+	if prof == nil || (len(prof.Words) == 0 && len(prof.Commands) == 0) {
+		return dict
+	}
+	return dict.WithLayer(internal.Layer{Source: "profile " + name, Words: prof.Words, Commands: prof.Commands})
+}
+
+// deliverText delivers text through out. With a profile newline key, that
+// key is sent in place of each line break, so a line break can be
+// Shift+Enter in a chat app where a pasted newline would send the message.
+func (p *Processor) deliverText(out internal.OutputMode, text string, prof *internal.Profile) error {
+	if prof == nil || prof.Newline == "" || !strings.Contains(text, "\n") {
+		return out.Deliver(text)
+	}
+	ch, err := internal.ParseChord(prof.Newline)
+	if err != nil {
+		return fmt.Errorf("profile newline: %w", err)
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		// Spaces the dictionary left around a spoken line break go with it.
+		if i > 0 {
+			if err := p.actions.sendChord(ch); err != nil {
+				return err
+			}
+			line = strings.TrimLeft(line, " \t")
+		}
+		if i < len(lines)-1 {
+			line = strings.TrimRight(line, " \t")
+		}
+		if line == "" {
+			continue
+		}
+		if err := out.Deliver(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sessionOutput returns the output mode the hotkey binding selects, or
+// else the one the profile selects, falling back to the processor's own.
+// The mode name is returned alongside it.
+func (p *Processor) sessionOutput(prof *internal.Profile) (internal.OutputMode, string) {
+	p.mu.Lock()
+	out, mode := p.out, p.cfg.OutputMode
+	want, source := p.binding.OutputMode, "Binding"
+	p.mu.Unlock()
+
+	if want == "" && prof != nil {
+		want, source = prof.OutputMode, "Profile"
+	}
+	if want == "" || want == mode {
+		return out, mode
+	}
+	cfg := *p.cfg
+	cfg.OutputMode = want
+	if po := resolveOutput(&cfg); po != nil {
+		return po, want
+	}
+	fmt.Fprintf(os.Stderr, "\n%s: unknown output mode: %s\n", source, want)
+	return out, mode
+}
@@ -0,0 +1,268 @@
+package processor
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/basilysf1709/golos/internal"
+)
+
+func newProfileProcessor(t *testing.T, app internal.AppInfo, profiles map[string]internal.Profile) (*Processor, *mockOutput) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	cfg := &internal.Config{DeepgramAPIKey: "test-key", OutputMode: "clipboard", Profiles: profiles}
+	out := &mockOutput{}
+	p, err := New(cfg, out)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	p.frontmostApp = func() (internal.AppInfo, error) { return app, nil }
+	p.workingDir = t.TempDir
+	return p, out
+}
+
+func TestDeliverUsesProfileWords(t *testing.T) {
+	p, out := newProfileProcessor(t, internal.AppInfo{Name: "Slack"}, map[string]internal.Profile{
+		"slack": {Apps: []string{"Slack"}, Words: map[string]string{"new line": "⏎"}},
+	})
+	p.deliver("hello new line world")
+	if out.delivered != "hello ⏎ world" {
+		t.Errorf("delivered = %q, want %q", out.delivered, "hello ⏎ world")
+	}
+}
+
+func TestDeliverWithoutMatchingProfile(t *testing.T) {
+	p, out := newProfileProcessor(t, internal.AppInfo{Name: "Terminal"}, map[string]internal.Profile{
+		"slack": {Apps: []string{"Slack"}, Words: map[string]string{"new line": "⏎"}},
+	})
+	p.deliver("hello new line world")
+	if out.delivered != "hello new line world" {
+		t.Errorf("delivered = %q, want untouched", out.delivered)
+	}
+}
+
+type lineOutput struct{ log *[]string }
+
+func (o lineOutput) Deliver(text string) error {
+	*o.log = append(*o.log, text)
+	return nil
+}
+
+func TestDeliverSendsProfileNewlineKey(t *testing.T) {
+	p, _ := newProfileProcessor(t, internal.AppInfo{Name: "Slack"}, map[string]internal.Profile{
+		"slack": {Apps: []string{"Slack"}, Newline: "shift+enter", Words: map[string]string{"new line": "\n"}},
+	})
+	var log []string
+	p.out = lineOutput{&log}
+	p.actions.sendChord = func(ch internal.Chord) error {
+		log = append(log, "<"+ch.String()+">")
+		return nil
+	}
+	p.deliver("first new line second")
+	want := []string{"first", "<shift+enter>", "second"}
+	if strings.Join(log, "|") != strings.Join(want, "|") {
+		t.Errorf("delivered %q, want %q", log, want)
+	}
+}
+
+func TestActiveProfileDetectionError(t *testing.T) {
+	p, _ := newProfileProcessor(t, internal.AppInfo{}, map[string]internal.Profile{
+		"slack": {Apps: []string{"Slack"}},
+	})
+	p.frontmostApp = func() (internal.AppInfo, error) { return internal.AppInfo{}, errors.New("no display") }
+	if _, name, prof := p.activeProfile(); name != "" || prof != nil {
+		t.Errorf("got %q %+v, want no profile", name, prof)
+	}
+}
+
+func TestActiveProfileSkipsDetectionWithoutProfiles(t *testing.T) {
+	p, _ := newProfileProcessor(t, internal.AppInfo{}, nil)
+	p.frontmostApp = func() (internal.AppInfo, error) {
+		t.Error("frontmost app looked up with no profiles configured")
+		return internal.AppInfo{}, nil
+	}
+	p.activeProfile()
+}
+
+func TestSessionOutput(t *testing.T) {
+	p, out := newProfileProcessor(t, internal.AppInfo{}, nil)
+
+	got, mode := p.sessionOutput(nil)
+	if got != out || mode != "clipboard" {
+		t.Errorf("no profile: got %T %q, want processor output", got, mode)
+	}
+
+	got, mode = p.sessionOutput(&internal.Profile{OutputMode: "type"})
+	if _, ok := got.(*internal.TypeMode); !ok || mode != "type" {
+		t.Errorf("type profile: got %T %q", got, mode)
+	}
+
+	got, mode = p.sessionOutput(&internal.Profile{OutputMode: "fax"})
+	if got != out || mode != "clipboard" {
+		t.Errorf("unknown mode: got %T %q, want fallback", got, mode)
+	}
+}
+
+func TestSessionDictLayering(t *testing.T) {
+	p, out := newProfileProcessor(t, internal.AppInfo{Name: "Slack"}, map[string]internal.Profile{
+		"slack": {Apps: []string{"Slack"}, Words: map[string]string{"deploy": "🚀"}},
+	})
+
+	project := t.TempDir()
+	dir := filepath.Join(project, ".golos")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data := "[words]\n\"deploy\" = \"make deploy\"\n\"cube\" = \"kube\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "dictionary.toml"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p.workingDir = func() string { return project }
+
+	// The profile is more specific than the project dictionary.
+	p.deliver("cube deploy")
+	if out.delivered != "kube 🚀" {
+		t.Errorf("delivered = %q, want %q", out.delivered, "kube 🚀")
+	}
+}
+
+func TestDeliverRecordsHistory(t *testing.T) {
+	p, _ := newProfileProcessor(t, internal.AppInfo{Name: "Slack"}, map[string]internal.Profile{
+		"slack": {Apps: []string{"Slack"}, Words: map[string]string{"new line": "⏎"}},
+	})
+	var got []internal.HistoryEntry
+	p.record = func(e internal.HistoryEntry) error {
+		got = append(got, e)
+		return nil
+	}
+	p.deliver("hello new line world")
+	if len(got) != 1 {
+		t.Fatalf("recorded %d entries, want 1", len(got))
+	}
+	e := got[0]
+	if e.Raw != "hello new line world" || e.Delivered != "hello ⏎ world" {
+		t.Errorf("entry = %+v", e)
+	}
+	if e.Output != "clipboard" || e.Profile != "slack" {
+		t.Errorf("output/profile = %q/%q, want clipboard/slack", e.Output, e.Profile)
+	}
+
+	p.cfg.Language = "auto"
+	p.languages = []string{"de"}
+	got = nil
+	p.deliver("hallo")
+	if len(got) != 1 || got[0].Language != "de" {
+		t.Errorf("entries = %+v, want the detected language recorded", got)
+	}
+}
+
+func TestDeliverFormatsAfterDictionary(t *testing.T) {
+	p, out := newProfileProcessor(t, internal.AppInfo{Name: "Terminal"}, map[string]internal.Profile{
+		"term": {Apps: []string{"Terminal"}, Words: map[string]string{"source": "src"}},
+	})
+	p.cfg.Format = internal.FormatConfig{Paths: true, Casing: true}
+	p.deliver("edit source slash snake case user store dot go")
+	if want := "edit src/user_store.go"; out.delivered != want {
+		t.Errorf("delivered = %q, want %q", out.delivered, want)
+	}
+}
+
+func TestDeliverRemovesFillersBeforeDictionary(t *testing.T) {
+	p, out := newProfileProcessor(t, internal.AppInfo{Name: "Slack"}, map[string]internal.Profile{
+		"slack": {Apps: []string{"Slack"}, Words: map[string]string{"uh oh": "😬"}},
+	})
+	p.cfg.Filler = internal.FillerConfig{Enabled: true, Repeats: true}
+	pipeline, err := internal.NewPipeline(p.cfg)
+	if err != nil {
+		t.Fatalf("NewPipeline: %v", err)
+	}
+	p.pipeline = pipeline
+
+	p.deliver("Um, the the build is uh oh broken")
+	if want := "The build is oh broken"; out.delivered != want {
+		t.Errorf("delivered = %q, want %q", out.delivered, want)
+	}
+}
+
+func TestPolishModifier(t *testing.T) {
+	p, out := newProfileProcessor(t, internal.AppInfo{}, nil)
+	if p.polishRequested() {
+		t.Error("no modifier configured: polish should not be requested")
+	}
+
+	mod, _ := internal.ParseModifiers("shift")
+	p.polishMod = &mod
+	held := false
+	p.modifiersHeld = func(ch internal.Chord) bool { return held && ch.Shift }
+
+	var sessions []internal.Session
+	p.pipeline.Add("spy", internal.TransformerFunc(func(text string, s internal.Session) (string, error) {
+		sessions = append(sessions, s)
+		return text, nil
+	}))
+
+	p.polish = p.polishRequested()
+	p.deliver("plain")
+	held = true
+	p.polish = p.polishRequested()
+	p.deliver("polished")
+
+	if len(sessions) != 2 || sessions[0].Polish || !sessions[1].Polish {
+		t.Errorf("sessions = %+v, want polish only for the second", sessions)
+	}
+	if out.delivered != "polished" {
+		t.Errorf("delivered = %q", out.delivered)
+	}
+}
+
+func TestBindingOverrides(t *testing.T) {
+	p, _ := newProfileProcessor(t, internal.AppInfo{}, nil)
+	p.cfg.Language = "en"
+	p.binding = internal.Binding{Hotkey: "right_command", Language: "de", OutputMode: "type"}
+
+	if lang := p.sessionLanguage(); lang != "de" {
+		t.Errorf("sessionLanguage = %q, want de", lang)
+	}
+	got, mode := p.sessionOutput(&internal.Profile{OutputMode: "stdout"})
+	if _, ok := got.(*internal.TypeMode); !ok || mode != "type" {
+		t.Errorf("binding output: got %T %q, want the binding's over the profile's", got, mode)
+	}
+
+	p.binding = internal.Binding{Hotkey: "right_option"}
+	if lang := p.sessionLanguage(); lang != "en" {
+		t.Errorf("sessionLanguage = %q, want the configured en", lang)
+	}
+	if _, mode := p.sessionOutput(&internal.Profile{OutputMode: "stdout"}); mode != "stdout" {
+		t.Errorf("mode = %q, want the profile's stdout", mode)
+	}
+}
+
+func TestDetectSpeech(t *testing.T) {
+	p, _ := newProfileProcessor(t, internal.AppInfo{}, nil)
+	silence := make([]int16, internal.FrameSamples)
+	tone := make([]int16, internal.FrameSamples)
+	for i := range tone {
+		tone[i] = int16(20000 * math.Sin(2*math.Pi*400*float64(i)/internal.SampleRate))
+	}
+
+	for i := 0; i < 50; i++ {
+		p.detectSpeech(silence)
+	}
+	if p.heard {
+		t.Fatal("silence counted as speech")
+	}
+	for i := 0; i < minVoiced-1; i++ {
+		p.detectSpeech(tone)
+	}
+	if p.heard {
+		t.Fatal("a few voiced frames counted as speech")
+	}
+	p.detectSpeech(tone)
+	if !p.heard {
+		t.Error("voiced frames not counted as speech")
+	}
+}
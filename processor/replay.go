@@ -0,0 +1,109 @@
+package processor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/basilysf1709/golos/internal"
+)
+
+// sideEffectStages are the pipeline stage types that reach outside
+// golos: exec runs shell commands and polish sends the text to an LLM.
+var sideEffectStages = []string{"exec", "polish"}
+
+// Replay streams recorded audio through the DSP chain, silence trimming
+// and the provider in real time, as the mic would, and returns the
+// transcript it produces now and the text the dictionary and pipeline
+// would deliver for it. Voice commands and the submit trigger are
+// stripped but not run, and nothing is delivered. The exec and polish
+// stages are skipped unless sideEffects is set.
+func (p *Processor) Replay(samples []int16, rate int, language string, sideEffects bool) (raw, text string, err error) {
+	if rate != p.rate {
+		samples = internal.NewResampler(rate, p.rate).Process(samples)
+	}
+	if language == "" {
+		language = p.cfg.Language
+	}
+
+	prov := internal.NewDeepgram(p.cfg.DeepgramAPIKey, language, p.cfg.Languages...)
+	prov.SetSampleRate(p.rate)
+	if err := prov.Connect(); err != nil {
+		return "", "", err
+	}
+	defer prov.Close()
+
+	p.mu.Lock()
+	p.binding = internal.Binding{Language: language}
+	p.transcript.Reset()
+	p.languages = nil
+	p.mu.Unlock()
+
+	sent := make(chan struct{})
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for {
+			select {
+			case result, ok := <-prov.Results():
+				if !ok {
+					return
+				}
+				p.mu.Lock()
+				p.takeResult(result)
+				p.mu.Unlock()
+			case <-sent:
+				p.drainResults(prov, 2*time.Second)
+				return
+			}
+		}
+	}()
+
+	if p.dsp != nil {
+		p.dsp.Reset()
+	}
+	if p.trim != nil {
+		p.trim.Reset()
+	}
+	p.vad.Reset()
+	size := internal.FrameSize(p.rate)
+	tick := time.NewTicker(internal.FrameDurMs * time.Millisecond)
+	for i := 0; i < len(samples); i += size {
+		<-tick.C
+		frame := samples[i:min(i+size, len(samples))]
+		if p.dsp != nil {
+			frame = p.dsp.Process(frame)
+		}
+		ev, _ := p.vad.Process(frame)
+		for _, f := range p.trimmed(frame, ev) {
+			if _, err := prov.Write(pcmBytes(f)); err != nil {
+				tick.Stop()
+				close(sent)
+				<-collected
+				return "", "", fmt.Errorf("send audio: %w", err)
+			}
+		}
+	}
+	tick.Stop()
+	_ = prov.Finalize()
+	close(sent)
+	<-collected
+	fmt.Print("\r\033[K")
+
+	p.mu.Lock()
+	raw = p.transcript.String()
+	p.mu.Unlock()
+
+	dir := p.workingDir()
+	dict := p.sessionDict(dir, "", nil)
+	text, _ = dict.ExtractCommands(raw)
+	text, _ = internal.StripTrigger(text, p.cfg.SubmitTriggers)
+	if text != "" {
+		session := internal.Session{Output: p.cfg.OutputMode, Dir: dir, Language: p.sessionLanguage(), Dict: dict}
+		pipeline := p.pipeline
+		if !sideEffects {
+			pipeline = pipeline.Without(sideEffectStages...)
+		}
+		text, err = pipeline.Run(text, session)
+	}
+	return raw, text, err
+}
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/gordonklaus/portaudio"
 
@@ -11,9 +12,13 @@ import (
 )
 
 type App struct {
-	Proc   *Processor
-	Hotkey internal.HotkeyInfo
-	Config *internal.Config
+	Proc     *Processor
+	Bindings []internal.Binding
+	Hotkeys  []internal.HotkeySpec // parsed hotkey for each binding
+	Cancel   internal.Chord        // key that discards the session; no Key if off
+	Ignore   internal.Chord        // modifiers that may be held with any hotkey
+	PreRoll  time.Duration         // audio kept from before the hotkey; 0 if off
+	Config   *internal.Config
 }
 
 func applyFlags(cfg *internal.Config, output, hotkey *string) {
@@ -31,13 +36,17 @@ func resolveOutput(cfg *internal.Config) internal.OutputMode {
 		return &internal.StdoutMode{}
 	case "clipboard":
 		return &internal.ClipboardMode{}
+	case "type":
+		return &internal.TypeMode{}
+	case "tmux":
+		return &internal.TmuxMode{Target: cfg.TmuxTarget}
 	default:
 		return nil
 	}
 }
 
 func Setup() (*App, error) {
-	outputFlag := flag.String("output", "", "output mode: clipboard or stdout (default: from config)")
+	outputFlag := flag.String("output", "", "output mode: clipboard, type, tmux or stdout (default: from config)")
 	hotkeyFlag := flag.String("hotkey", "", "push-to-talk hotkey (default: from config)")
 	flag.Parse()
 
@@ -53,8 +62,73 @@ func Setup() (*App, error) {
 		return nil, fmt.Errorf("unknown output mode: %s", cfg.OutputMode)
 	}
 
-	// Check accessibility permission for clipboard mode
-	if cfg.OutputMode == "clipboard" {
+	bindings, err := cfg.HotkeyBindings()
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse hotkeys
+	hotkeys := make([]internal.HotkeySpec, len(bindings))
+	needsAccess := cfg.OutputMode == "clipboard" || cfg.OutputMode == "type"
+	for i, b := range bindings {
+		hotkeys[i], err = internal.ParseHotkey(b.Hotkey)
+		if err != nil {
+			return nil, err
+		}
+		if b.OutputMode == "" {
+			continue
+		}
+		bcfg := *cfg
+		bcfg.OutputMode = b.OutputMode
+		if resolveOutput(&bcfg) == nil {
+			return nil, fmt.Errorf("binding %s: unknown output mode: %s", b.Hotkey, b.OutputMode)
+		}
+		needsAccess = needsAccess || b.OutputMode == "clipboard" || b.OutputMode == "type"
+	}
+	for name, prof := range cfg.Profiles {
+		if prof.OutputMode != "" {
+			pcfg := *cfg
+			pcfg.OutputMode = prof.OutputMode
+			if resolveOutput(&pcfg) == nil {
+				return nil, fmt.Errorf("profile %s: unknown output mode: %s", name, prof.OutputMode)
+			}
+			needsAccess = needsAccess || prof.OutputMode == "clipboard" || prof.OutputMode == "type"
+		}
+		if prof.Newline != "" {
+			if _, err := internal.ParseChord(prof.Newline); err != nil {
+				return nil, fmt.Errorf("profile %s: newline: %w", name, err)
+			}
+			needsAccess = true
+		}
+	}
+
+	cancel, err := cfg.CancelChord()
+	if err != nil {
+		return nil, err
+	}
+	// The polish modifier is held together with a hotkey, so it must not
+	// stop the hotkey from matching.
+	var ignore internal.Chord
+	if cfg.Polish.Modifier != "" {
+		if ignore, err = internal.ParseModifiers(cfg.Polish.Modifier); err != nil {
+			return nil, fmt.Errorf("polish modifier: %w", err)
+		}
+	}
+	var preRoll time.Duration
+	if cfg.PreRoll != "" {
+		if preRoll, err = time.ParseDuration(cfg.PreRoll); err != nil {
+			return nil, fmt.Errorf("pre_roll: %w", err)
+		}
+	}
+	// An always-open mic is only allowed while the overlay is there to
+	// show it.
+	if preRoll > 0 && !cfg.Overlay {
+		fmt.Fprintln(os.Stderr, "pre_roll needs the overlay on; recording starts with the hotkey instead")
+		preRoll = 0
+	}
+
+	// Check accessibility permission for modes that post key events
+	if needsAccess {
 		if !internal.CheckAccessibility() {
 			fmt.Fprintln(os.Stderr, "")
 			fmt.Fprintln(os.Stderr, "  Accessibility permission required!")
@@ -65,12 +139,6 @@ func Setup() (*App, error) {
 		}
 	}
 
-	// Resolve hotkey
-	hk, err := internal.ResolveHotkey(cfg.Hotkey)
-	if err != nil {
-		return nil, err
-	}
-
 	// Initialize PortAudio
 	if err := portaudio.Initialize(); err != nil {
 		return nil, fmt.Errorf("PortAudio init: %w", err)
@@ -82,6 +150,20 @@ func Setup() (*App, error) {
 		_ = portaudio.Terminate()
 		return nil, err
 	}
+	if preRoll > 0 {
+		standby, err := internal.NewStandby(preRoll, proc.rate)
+		if err != nil {
+			_ = portaudio.Terminate()
+			return nil, fmt.Errorf("pre-roll: %w", err)
+		}
+		proc.UseStandby(standby)
+	}
+
+	return &App{Proc: proc, Bindings: bindings, Hotkeys: hotkeys, Cancel: cancel, Ignore: ignore, PreRoll: preRoll, Config: cfg}, nil
+}
 
-	return &App{Proc: proc, Hotkey: hk, Config: cfg}, nil
+// Close releases the always-open mic, if any. Call it before terminating
+// PortAudio.
+func (a *App) Close() {
+	a.Proc.Close()
 }
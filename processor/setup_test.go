@@ -28,6 +28,24 @@ func TestResolveOutputClipboard(t *testing.T) {
 	}
 }
 
+func TestResolveOutputType(t *testing.T) {
+	cfg := &internal.Config{OutputMode: "type"}
+	if _, ok := resolveOutput(cfg).(*internal.TypeMode); !ok {
+		t.Errorf("expected *TypeMode, got %T", resolveOutput(cfg))
+	}
+}
+
+func TestResolveOutputTmux(t *testing.T) {
+	cfg := &internal.Config{OutputMode: "tmux", TmuxTarget: "work:1"}
+	out, ok := resolveOutput(cfg).(*internal.TmuxMode)
+	if !ok {
+		t.Fatalf("expected *TmuxMode, got %T", resolveOutput(cfg))
+	}
+	if out.Target != "work:1" {
+		t.Errorf("Target = %q, want %q", out.Target, "work:1")
+	}
+}
+
 func TestResolveOutputUnknown(t *testing.T) {
 	cfg := &internal.Config{OutputMode: "fax"}
 	out := resolveOutput(cfg)